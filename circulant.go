@@ -0,0 +1,101 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// checkBlockCirculant returns an error unless M is an n x n matrix of ring
+// elements in which every row is the previous one rotated right by one
+// block, that is, M[i][j] = M[0][(j-i) mod n]. As the blocks may be secret,
+// every pair of blocks is compared in full before the result is tested, and
+// the error does not say where the structure fails; only the shape of M and
+// the presence of nil blocks are checked in variable time.
+func checkBlockCirculant(name string, M [][]*[768]int32, n int) error {
+	if len(M) != n {
+		return fmt.Errorf("%s has %d rows, want %d", name, len(M), n)
+	}
+	for i := range M {
+		if len(M[i]) != n {
+			return fmt.Errorf("%s row %d has %d blocks, want %d", name, i, len(M[i]), n)
+		}
+		for j := range M[i] {
+			if M[i][j] == nil {
+				return fmt.Errorf("%s[%d][%d] is nil", name, i, j)
+			}
+		}
+	}
+	var v int32
+	for i := 1; i < n; i++ {
+		for j := 0; j < n; j++ {
+			a, b := M[i][j], M[0][(j-i+n)%n]
+			for k := range a {
+				v |= a[k] ^ b[k]
+			}
+		}
+	}
+	if subtle.ConstantTimeEq(v, 0) != 1 {
+		return fmt.Errorf("%s is not block-circulant", name)
+	}
+	return nil
+}
+
+// BlockCirculantMul sets H to the matrix product F*G, where F and G are n x n
+// block-circulant matrices of elements of Z_9829[x]/(r(x)), with r given by
+// ringMod, and H is an n x n matrix of preallocated blocks. The product of two
+// block-circulant matrices is block-circulant, so only the first row of H is
+// computed, with n^2 calls to MulRing instead of n^3; the remaining rows are
+// rotations of it. An error is returned if the matrices do not have this
+// structure or if ringMod is invalid.
+func BlockCirculantMul(H [][]*[768]int32, F, G [][]*[768]int32, ringMod *[769]int32) error {
+	n := len(F)
+	if n == 0 {
+		return errors.New("empty matrix")
+	}
+	if err := checkBlockCirculant("F", F, n); err != nil {
+		return err
+	}
+	if err := checkBlockCirculant("G", G, n); err != nil {
+		return err
+	}
+	if len(H) != n {
+		return fmt.Errorf("H has %d rows, want %d", len(H), n)
+	}
+	for i := range H {
+		if len(H[i]) != n {
+			return fmt.Errorf("H row %d has %d blocks, want %d", i, len(H[i]), n)
+		}
+		for j := range H[i] {
+			if H[i][j] == nil {
+				return fmt.Errorf("H[%d][%d] is nil", i, j)
+			}
+		}
+	}
+	m, err := monicModulus(ringMod)
+	if err != nil {
+		return err
+	}
+
+	// H[0][j] = sum_k F[0][k] * G[k][j]
+	row := make([]*[768]int32, n)
+	t := new([768]int32)
+	for j := range row {
+		row[j] = new([768]int32)
+		for k := 0; k < n; k++ {
+			m.mul(t, F[0][k], G[k][j])
+			thinPoly(row[j][:]).Add(row[j][:], t[:])
+		}
+	}
+	for i := range H {
+		for j := range H[i] {
+			*H[i][j] = *row[(j-i+n)%n]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+// randCirculant returns a random n x n block-circulant matrix.
+func randCirculant(n int) [][]*[768]int32 {
+	row := make([]*[768]int32, n)
+	for j := range row {
+		row[j] = randPoly(new([768]int32))
+	}
+	return circulant(row)
+}
+
+// circulant returns the block-circulant matrix whose first row is row.
+func circulant(row []*[768]int32) [][]*[768]int32 {
+	n := len(row)
+	M := make([][]*[768]int32, n)
+	for i := range M {
+		M[i] = make([]*[768]int32, n)
+		for j := range M[i] {
+			M[i][j] = new([768]int32)
+			*M[i][j] = *row[(j-i+n)%n]
+		}
+	}
+	return M
+}
+
+// newMatrix returns an n x n matrix of zero blocks.
+func newMatrix(n int) [][]*[768]int32 {
+	M := make([][]*[768]int32, n)
+	for i := range M {
+		M[i] = make([]*[768]int32, n)
+		for j := range M[i] {
+			M[i][j] = new([768]int32)
+		}
+	}
+	return M
+}
+
+func TestBlockCirculantMul(t *testing.T) {
+	r := ntruModulus()
+	for n := 1; n <= 3; n++ {
+		F := randCirculant(n)
+		G := randCirculant(n)
+		H := newMatrix(n)
+		if err := BlockCirculantMul(H, F, G, r); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				c := new([768]int32)
+				for k := 0; k < n; k++ {
+					d := new([768]int32)
+					textbookMulRing(d, F[i][k], G[k][j])
+					thinPoly(c[:]).Add(c[:], d[:])
+				}
+				if *c != *H[i][j] {
+					t.Fatalf("c != H[%d][%d] for n=%d", i, j, n)
+				}
+			}
+		}
+	}
+}
+
+func TestBlockCirculantMulAssociative(t *testing.T) {
+	r := ntruModulus()
+	F, G, K := randCirculant(2), randCirculant(2), randCirculant(2)
+	FG, GK, X, Y := newMatrix(2), newMatrix(2), newMatrix(2), newMatrix(2)
+	for _, err := range []error{
+		BlockCirculantMul(FG, F, G, r),
+		BlockCirculantMul(X, FG, K, r),
+		BlockCirculantMul(GK, G, K, r),
+		BlockCirculantMul(Y, F, GK, r),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range X {
+		for j := range X[i] {
+			if *X[i][j] != *Y[i][j] {
+				t.Fatalf("(FG)K != F(GK) at [%d][%d]", i, j)
+			}
+		}
+	}
+}
+
+func TestBlockCirculantMulInvalid(t *testing.T) {
+	r := ntruModulus()
+	F := randCirculant(2)
+	G := randCirculant(2)
+	H := newMatrix(2)
+	G[1][0][5]++
+	if err := BlockCirculantMul(H, F, G, r); err == nil {
+		t.Fatal("BlockCirculantMul accepted a matrix that is not block-circulant")
+	}
+	if err := BlockCirculantMul(H, F, randCirculant(3), r); err == nil {
+		t.Fatal("BlockCirculantMul accepted matrices of different sizes")
+	}
+	if err := BlockCirculantMul(H[:1], F, F, r); err == nil {
+		t.Fatal("BlockCirculantMul accepted a result of the wrong size")
+	}
+}
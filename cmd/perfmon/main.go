@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+// Perfmon benchmarks the multiplication routines of karatsuba768 and appends
+// the results, one JSON object per line, to a performance log, such as
+//
+//	{"function":"Mul","ns_per_op":215000,"commit":"abc123"}
+//
+// Usage:
+//
+//	perfmon [-output file] [-commit id]
+//
+// The log is written to standard output if no file is given. The commit
+// identifier is recorded as is, so that runs can be compared over time, e.g.
+// perfmon -output perf.log -commit $(git rev-parse --short HEAD).
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/martelletto/karatsuba768"
+)
+
+type result struct {
+	Function string `json:"function"`
+	NsPerOp  int64  `json:"ns_per_op"`
+	Commit   string `json:"commit"`
+}
+
+func randPoly() *[768]int32 {
+	f := new([768]int32)
+	for i := range f {
+		f[i] = int32(rand.Intn(9829))
+	}
+	return f
+}
+
+var benchmarks = []struct {
+	name string
+	fn   func(b *testing.B)
+}{
+	{"Mul", func(b *testing.B) {
+		f, g := randPoly(), randPoly()
+		h := new([1536]int32)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			karatsuba768.Mul(h, f, g)
+		}
+	}},
+	{"SafeMul", func(b *testing.B) {
+		f, g := randPoly(), randPoly()
+		h := new([1536]int32)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := karatsuba768.SafeMul(h, f, g); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}},
+}
+
+func main() {
+	output := flag.String("output", "", "append results to `file` instead of standard output")
+	commit := flag.String("commit", "", "commit `id` to record with the results")
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("perfmon: ")
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	for _, bm := range benchmarks {
+		r := testing.Benchmark(bm.fn)
+		if r.N == 0 {
+			log.Fatalf("%s: benchmark failed", bm.name)
+		}
+		err := enc.Encode(result{bm.name, r.NsPerOp(), *commit})
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
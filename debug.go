@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build debug
+
+package karatsuba768
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// debugBuild is set when the package is built with the debug tag, which
+// enables the checks in this file. They are development aids and have no
+// place in production builds.
+const debugBuild = true
+
+var freezePanic bool
+
+// SetFreezePanic makes Freeze panic on inputs outside (-165191050,
+// +165191050), for which it silently returns a wrong result. This is meant to
+// locate overflows while developing the algorithm. With the debug tag, the
+// vectorised slice reductions are disabled so that every reduction goes
+// through Freeze.
+func SetFreezePanic(enable bool) {
+	freezePanic = enable
+}
+
+func checkFreeze(x int32) {
+	if freezePanic && (x <= -165191050 || x >= 165191050) {
+		panic(fmt.Sprintf("Freeze: input %d out of range", x))
+	}
+}
+
+// checkInc panics if adding x to p takes a coefficient outside the domain of
+// Freeze.
+func checkInc(p, x []int32) {
+	for i := range x {
+		if v := int64(p[i]) + int64(x[i]); v <= -165191050 || v >= 165191050 {
+			panic(fmt.Sprintf("Inc: coefficient %d overflows: %d", i, v))
+		}
+	}
+}
+
+// checkAlias panics if the first len(x) elements of p overlap x without
+// starting at the same address.
+func checkAlias(p, x []int32) {
+	if len(x) == 0 || len(p) == 0 {
+		return
+	}
+	n := len(x)
+	if len(p) < n {
+		n = len(p)
+	}
+	pp := uintptr(unsafe.Pointer(&p[0]))
+	xp := uintptr(unsafe.Pointer(&x[0]))
+	if pp != xp && pp < xp+4*uintptr(len(x)) && xp < pp+4*uintptr(n) {
+		panic("thinPoly: partially overlapping operands")
+	}
+}
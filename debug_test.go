@@ -0,0 +1,63 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build debug
+
+package karatsuba768
+
+import "testing"
+
+func TestSetFreezePanic(t *testing.T) {
+	SetFreezePanic(true)
+	defer SetFreezePanic(false)
+
+	for _, x := range []int32{-165191049, 0, 165191049} {
+		Freeze(x)
+	}
+	for _, x := range []int32{-165191050, 165191050} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("Freeze(%d) did not panic", x)
+				}
+			}()
+			Freeze(x)
+		}()
+	}
+
+	a := randPoly(new([768]int32))
+	b := randPoly(new([768]int32))
+	Mul(new([1536]int32), a, b)
+}
+
+func TestCheckInc(t *testing.T) {
+	p := thinPoly{0, 165191049, -165191049}
+	p.Inc([]int32{165191049, 0, 0})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Inc did not panic")
+		}
+	}()
+	p.Inc([]int32{0, 1, 0})
+}
+
+func TestCheckAlias(t *testing.T) {
+	poly := make(thinPoly, 16)
+	poly.Add(poly, poly)
+	poly[:8].Add(poly[:8], poly[8:])
+	for _, f := range []func(){
+		func() { poly[4:].Add(poly[:12], poly[:12]) },
+		func() { poly[:12].Add(poly[4:], poly[:12]) },
+		func() { poly[:8].Add(poly[:8], poly[4:12]) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("Add did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// packedSize is the length in bytes of a polynomial packed by pack.
+const packedSize = 768 * 14 / 8
+
+// pack packs the coefficients of f, which must be in [0, 9828], into dst as
+// consecutive 14-bit little-endian fields. Every four coefficients fill
+// exactly seven bytes.
+func pack(dst []byte, f *[768]int32) {
+	for i := 0; i < 192; i++ {
+		w := uint64(f[4*i]&0x3fff) |
+			uint64(f[4*i+1]&0x3fff)<<14 |
+			uint64(f[4*i+2]&0x3fff)<<28 |
+			uint64(f[4*i+3]&0x3fff)<<42
+		for j := 0; j < 7; j++ {
+			dst[7*i+j] = byte(w >> (8 * j))
+		}
+	}
+}
+
+// unpack is the inverse of pack. It does not validate the coefficients.
+func unpack(f *[768]int32, src []byte) {
+	for i := 0; i < 192; i++ {
+		var w uint64
+		for j := 0; j < 7; j++ {
+			w |= uint64(src[7*i+j]) << (8 * j)
+		}
+		f[4*i] = int32(w & 0x3fff)
+		f[4*i+1] = int32(w >> 14 & 0x3fff)
+		f[4*i+2] = int32(w >> 28 & 0x3fff)
+		f[4*i+3] = int32(w >> 42 & 0x3fff)
+	}
+}
+
+// EncodedSize is the length in bytes of a polynomial encoded by Encode.
+const EncodedSize = packedSize
+
+// validCT reports whether every coefficient of f is in [0, 9828], in
+// constant time. A coefficient is out of range if and only if x or 9828 - x
+// is negative. The loop is branch-free and keeps four independent
+// accumulators, one per lane of a 128-bit vector.
+func validCT(f *[768]int32) bool {
+	var v0, v1, v2, v3 int32
+	for i := 0; i < 768; i += 4 {
+		x := (*[4]int32)(f[i : i+4])
+		v0 |= x[0] | (9828 - x[0])
+		v1 |= x[1] | (9828 - x[1])
+		v2 |= x[2] | (9828 - x[2])
+		v3 |= x[3] | (9828 - x[3])
+	}
+	return v0|v1|v2|v3 >= 0
+}
+
+// Encode packs f into dst as 768 consecutive 14-bit little-endian fields,
+// every four coefficients filling seven bytes. An error is returned if dst is
+// shorter than EncodedSize bytes or if a coefficient of f is not in
+// [0, 9828]. Its running time does not depend on the coefficients of f.
+func Encode(dst []byte, f *[768]int32) error {
+	if len(dst) < EncodedSize {
+		return errors.New("buffer too small")
+	}
+	if !validCT(f) {
+		return errors.New("coefficient out of range")
+	}
+	pack(dst, f)
+	return nil
+}
+
+// Decode unpacks a polynomial encoded by Encode into f. An error is returned,
+// and f is left untouched, if src is not EncodedSize bytes long or if a
+// decoded coefficient is not in [0, 9828]. Its running time does not depend on
+// the contents of src, and the error does not say which coefficient is out of
+// range.
+func Decode(f *[768]int32, src []byte) error {
+	if len(src) != EncodedSize {
+		return errors.New("invalid length")
+	}
+	var t [768]int32
+	unpack(&t, src)
+	if !validCT(&t) {
+		return errors.New("coefficient out of range")
+	}
+	*f = t
+	return nil
+}
+
+// EncodedSmallSize is the length in bytes of a polynomial encoded by
+// EncodeSmall.
+const EncodedSmallSize = 768 * 2 / 8
+
+// EncodeSmall packs f, which must be ternary, into dst at two bits per
+// coefficient, four coefficients per byte starting with the low bits: 00 for
+// 0, 01 for 1 and 10 for 9828, that is, -1. An error is returned if dst is
+// shorter than EncodedSmallSize bytes or if a coefficient of f is not 0, 1 or
+// 9828. Its running time does not depend on the coefficients of f.
+func EncodeSmall(dst []byte, f *[768]int32) error {
+	if len(dst) < EncodedSmallSize {
+		return errors.New("buffer too small")
+	}
+	if !IsSmall(f) {
+		return errors.New("coefficient not in {0, 1, 9828}")
+	}
+	for i := 0; i < EncodedSmallSize; i++ {
+		var b byte
+		for j := 0; j < 4; j++ {
+			x := f[4*i+j]
+			c := x&1 | int32(subtle.ConstantTimeEq(x, 9828))<<1
+			b |= byte(c) << (2 * j)
+		}
+		dst[i] = b
+	}
+	return nil
+}
+
+// DecodeSmall unpacks a polynomial encoded by EncodeSmall into f. An error is
+// returned, and f is left untouched, if src is not EncodedSmallSize bytes
+// long or if it holds the reserved pair 11. Its running time does not depend
+// on the contents of src.
+func DecodeSmall(f *[768]int32, src []byte) error {
+	if len(src) != EncodedSmallSize {
+		return errors.New("invalid length")
+	}
+	var t [768]int32
+	var bad int32
+	for i, b := range src {
+		for j := 0; j < 4; j++ {
+			c := int32(b>>(2*j)) & 3
+			bad |= c & (c >> 1)
+			t[4*i+j] = c&1 + 9828*(c>>1)
+		}
+	}
+	if bad != 0 {
+		return errors.New("invalid coefficient encoding")
+	}
+	*f = t
+	return nil
+}
+
+// HexEncode returns the hexadecimal encoding of f packed at 14 bits per
+// coefficient, a string of 2688 characters. The coefficients of f must be in
+// [0, 9828].
+func HexEncode(f *[768]int32) string {
+	var buf [packedSize]byte
+	pack(buf[:], f)
+	return hex.EncodeToString(buf[:])
+}
+
+// HexDecode decodes a polynomial encoded by HexEncode. An error is returned
+// if s is not 2688 hexadecimal characters long or if a decoded coefficient is
+// not in [0, 9828]. The bytes are decoded by Decode, and so are checked in
+// constant time.
+func HexDecode(s string) (*[768]int32, error) {
+	if len(s) != 2*EncodedSize {
+		return nil, errors.New("invalid length")
+	}
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	f := new([768]int32)
+	if err := Decode(f, buf); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// appendCoeffs appends the coefficients of p to buf in decimal, separated by
+// ", ".
+func appendCoeffs(buf []byte, p []int32) []byte {
+	for i := range p {
+		if i > 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = strconv.AppendInt(buf, int64(p[i]), 10)
+	}
+	return buf
+}
+
+// parseCoeffs parses up to len(p) comma-separated decimal coefficients from
+// text into p, which is left untouched on error. Missing coefficients of
+// higher degree are set to zero.
+func parseCoeffs(p []int32, text []byte) error {
+	parts := bytes.Split(text, []byte(","))
+	if len(parts) > len(p) {
+		return errors.New("too many parts")
+	}
+	q := make([]int32, len(p))
+	for i := range parts {
+		n, err := strconv.ParseInt(string(bytes.TrimSpace(parts[i])), 10, 32)
+		if err != nil {
+			return err
+		}
+		if n < 0 || n > 9828 {
+			return fmt.Errorf("coefficient %d out of range: %d", i, n)
+		}
+		q[i] = int32(n)
+	}
+	copy(p, q)
+	return nil
+}
+
+// readLine reads from r up to and including the next newline, which is not
+// returned. It returns io.EOF only if r is exhausted before any byte is read.
+// Unless r is an io.ByteReader, it is read one byte at a time.
+func readLine(r io.Reader) ([]byte, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = &byteReader{r: r}
+	}
+	var line []byte
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF && len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c == '\n' {
+			return bytes.TrimSuffix(line, []byte("\r")), nil
+		}
+		line = append(line, c)
+	}
+}
+
+// byteReader turns an io.Reader into an io.ByteReader without reading ahead.
+type byteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(b.r, b.buf[:])
+	return b.buf[0], err
+}
+
+// MarshalText implements encoding.TextMarshaler. The text form of p is the
+// list of its coefficients in decimal, separated by ", ", which is the format
+// of the polynomials in sage64.gz.
+func (p *Poly768) MarshalText() ([]byte, error) {
+	return appendCoeffs(make([]byte, 0, 768*6), p[:]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts up to 768
+// comma-separated decimal coefficients, surrounded by optional whitespace;
+// missing coefficients of higher degree are set to zero. An error is
+// returned if a coefficient is not in [0, 9828].
+func (p *Poly768) UnmarshalText(text []byte) error {
+	return parseCoeffs(p[:], text)
+}
+
+// binaryVersion is the version of the format of MarshalBinary. Its header is
+// a little-endian uint32 holding the version in the low 16 bits and the
+// modulus in the high 16 bits.
+const binaryVersion = 1
+
+// binaryHeader is the header of the format of MarshalBinary.
+const binaryHeader = binaryVersion | 9829<<16
+
+// MarshalBinary implements encoding.BinaryMarshaler. It returns a 4-byte
+// header, holding the version of the format and the modulus, followed by the
+// encoding of p by Encode, 1348 bytes in all. An error is returned if a
+// coefficient of p is not in [0, 9828].
+func (p *Poly768) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 4+EncodedSize)
+	binary.LittleEndian.PutUint32(buf, binaryHeader)
+	if err := Encode(buf[4:], (*[768]int32)(p)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. An error is
+// returned, and p is left untouched, if data is not 1348 bytes long, if its
+// header does not match that of MarshalBinary, or if a coefficient is not in
+// [0, 9828].
+func (p *Poly768) UnmarshalBinary(data []byte) error {
+	if len(data) != 4+EncodedSize {
+		return errors.New("invalid length")
+	}
+	h := binary.LittleEndian.Uint32(data)
+	if h&0xffff != binaryVersion {
+		return fmt.Errorf("unsupported version %d", h&0xffff)
+	}
+	if h>>16 != 9829 {
+		return fmt.Errorf("wrong modulus %d", h>>16)
+	}
+	return Decode((*[768]int32)(p), data[4:])
+}
+
+// JSONPoly768 is a polynomial that is encoded in JSON as an array of its 768
+// coefficients, rather than as the string given by Poly768.MarshalText.
+type JSONPoly768 [768]int32
+
+// Poly returns p as a *[768]int32.
+func (p *JSONPoly768) Poly() *[768]int32 {
+	return (*[768]int32)(p)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *JSONPoly768) MarshalJSON() ([]byte, error) {
+	buf := make([]byte, 0, 768*5+1)
+	buf = append(buf, '[')
+	for i := range p {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendInt(buf, int64(p[i]), 10)
+	}
+	return append(buf, ']'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts an array of exactly
+// 768 integers in [0, 9828], and leaves p untouched otherwise.
+func (p *JSONPoly768) UnmarshalJSON(data []byte) error {
+	var c []int64
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	if len(c) != 768 {
+		return fmt.Errorf("%d coefficients, want 768", len(c))
+	}
+	for i, x := range c {
+		if x < 0 || x > 9828 {
+			return fmt.Errorf("coefficient %d out of range: %d", i, x)
+		}
+	}
+	for i, x := range c {
+		p[i] = int32(x)
+	}
+	return nil
+}
+
+// ReadPoly reads one line from r in the format of UnmarshalText, which is
+// that of sage64.gz, into f. It returns io.EOF if there are no more lines,
+// and leaves f untouched on error. r is read one byte at a time unless it is
+// an io.ByteReader such as a bufio.Reader, which callers reading many
+// polynomials should use.
+func ReadPoly(r io.Reader, f *[768]int32) error {
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	return parseCoeffs(f[:], line)
+}
+
+// WritePoly writes f to w as one line in the format of MarshalText.
+func WritePoly(w io.Writer, f *[768]int32) error {
+	buf := appendCoeffs(make([]byte, 0, 768*6+1), f[:])
+	_, err := w.Write(append(buf, '\n'))
+	return err
+}
+
+// ReadResult is like ReadPoly, for a product of 1536 coefficients.
+func ReadResult(r io.Reader, h *[1536]int32) error {
+	line, err := readLine(r)
+	if err != nil {
+		return err
+	}
+	return parseCoeffs(h[:], line)
+}
+
+// WriteResult is like WritePoly, for a product of 1536 coefficients.
+func WriteResult(w io.Writer, h *[1536]int32) error {
+	buf := appendCoeffs(make([]byte, 0, 1536*6+1), h[:])
+	_, err := w.Write(append(buf, '\n'))
+	return err
+}
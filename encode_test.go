@@ -0,0 +1,376 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	buf := make([]byte, EncodedSize)
+	for i := 0; i < 16; i++ {
+		f := randPoly(new([768]int32))
+		if i == 0 {
+			for j := range f {
+				f[j] = 9828
+			}
+		}
+		if err := Encode(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		g := new([768]int32)
+		if err := Decode(g, buf); err != nil {
+			t.Fatal(err)
+		}
+		if *f != *g {
+			t.Fatalf("f != g for i=%d", i)
+		}
+	}
+
+	// 14-bit fields, little-endian: 0x2345 | 0x1abc<<14 = 0x6af2345
+	f := &[768]int32{0x2345, 0x1abc}
+	if err := Encode(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x45, 0x23, 0xaf, 0x06}
+	if !bytes.Equal(buf[:4], want) {
+		t.Fatalf("buf=%x, want %x", buf[:4], want)
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	f := randPoly(new([768]int32))
+	if err := Encode(make([]byte, EncodedSize-1), f); err == nil {
+		t.Fatal("Encode accepted a short buffer")
+	}
+	for _, x := range []int32{-1, 9829, 1 << 14, -1 << 31} {
+		f[100] = x
+		if err := Encode(make([]byte, EncodedSize), f); err == nil {
+			t.Fatalf("Encode accepted %d", x)
+		}
+	}
+
+	buf := make([]byte, EncodedSize)
+	g := new([768]int32)
+	if err := Decode(g, buf[1:]); err == nil {
+		t.Fatal("Decode accepted a short buffer")
+	}
+	buf[7*100], buf[7*100+1] = 0xff, 0x3f // 16383
+	g[0] = 1
+	if err := Decode(g, buf); err == nil {
+		t.Fatal("Decode accepted an out of range coefficient")
+	}
+	if *g != [768]int32{1} {
+		t.Fatal("Decode wrote to f on error")
+	}
+}
+
+func TestEncodeSmallRoundTrip(t *testing.T) {
+	buf := make([]byte, EncodedSmallSize)
+	for _, w := range []int{0, 1, 286, 768} {
+		f := randTernary(w)
+		if err := EncodeSmall(buf, f); err != nil {
+			t.Fatal(err)
+		}
+		g := new([768]int32)
+		if err := DecodeSmall(g, buf); err != nil {
+			t.Fatal(err)
+		}
+		if *f != *g {
+			t.Fatalf("f != g for weight %d", w)
+		}
+	}
+
+	// 0, 1, -1, 0, then 1: 0b00_10_01_00, 0b01
+	f := &[768]int32{0, 1, 9828, 0, 1}
+	if err := EncodeSmall(buf, f); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x24, 0x01, 0x00}
+	if !bytes.Equal(buf[:3], want) {
+		t.Fatalf("buf=%x, want %x", buf[:3], want)
+	}
+}
+
+func TestEncodeSmallInvalid(t *testing.T) {
+	f := randTernary(100)
+	if err := EncodeSmall(make([]byte, EncodedSmallSize-1), f); err == nil {
+		t.Fatal("EncodeSmall accepted a short buffer")
+	}
+	for _, x := range []int32{-1, 2, 9827, 9829} {
+		f[100] = x
+		if err := EncodeSmall(make([]byte, EncodedSmallSize), f); err == nil {
+			t.Fatalf("EncodeSmall accepted %d", x)
+		}
+	}
+
+	buf := make([]byte, EncodedSmallSize)
+	g := new([768]int32)
+	if err := DecodeSmall(g, buf[1:]); err == nil {
+		t.Fatal("DecodeSmall accepted a short buffer")
+	}
+	buf[191] = 0xc0 // 11 for the last coefficient
+	g[0] = 1
+	if err := DecodeSmall(g, buf); err == nil {
+		t.Fatal("DecodeSmall accepted the reserved pair 11")
+	}
+	if *g != [768]int32{1} {
+		t.Fatal("DecodeSmall wrote to f on error")
+	}
+}
+
+func TestHexRoundTrip(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		f := randPoly(new([768]int32))
+		if i == 0 {
+			for j := range f {
+				f[j] = 9828
+			}
+		}
+		s := HexEncode(f)
+		if len(s) != 2688 {
+			t.Fatalf("len(s)=%d", len(s))
+		}
+		g, err := HexDecode(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *f != *g {
+			t.Fatalf("f != g for i=%d", i)
+		}
+	}
+}
+
+func TestHexDecodeInvalid(t *testing.T) {
+	s := HexEncode(randPoly(new([768]int32)))
+	for i, bad := range []string{
+		"",
+		s[:2686],
+		s + "00",
+		"zz" + s[2:],
+		"ff3f" + s[4:], // first coefficient is 0x3fff
+	} {
+		if _, err := HexDecode(bad); err == nil {
+			t.Fatalf("HexDecode accepted invalid input %d", i)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	p := (*Poly768)(randPoly(new([768]int32)))
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := new(Poly768)
+	if err := q.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if *p != *q {
+		t.Fatal("p != q")
+	}
+}
+
+func TestUnmarshalTextSage(t *testing.T) {
+	in, err := openVectors("sage64.gz")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	buf := bufio.NewReaderSize(in, 1<<14)
+	line, _, err := buf.ReadLine()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := new([768]int32)
+	if err := ReadPoly(bytes.NewReader(line), a); err != nil {
+		t.Fatal(err)
+	}
+	p := new(Poly768)
+	if err := p.UnmarshalText(line); err != nil {
+		t.Fatal(err)
+	}
+	if *p != Poly768(*a) {
+		t.Fatal("p != a")
+	}
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(text, line) {
+		t.Fatal("MarshalText does not reproduce the sage64.gz line")
+	}
+}
+
+func TestUnmarshalTextInvalid(t *testing.T) {
+	for _, bad := range []string{"1, x", "1, 9829", "-1", "1,, 2"} {
+		if err := new(Poly768).UnmarshalText([]byte(bad)); err == nil {
+			t.Fatalf("UnmarshalText accepted %q", bad)
+		}
+	}
+}
+
+func TestReadWritePoly(t *testing.T) {
+	var buf bytes.Buffer
+	f := randPoly(new([768]int32))
+	h := new([1536]int32)
+	Mul(h, f, f)
+	if err := WritePoly(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteResult(&buf, h); err != nil {
+		t.Fatal(err)
+	}
+	if err := WritePoly(&buf, f); err != nil {
+		t.Fatal(err)
+	}
+
+	// a bytes.Reader is an io.ByteReader; hide it to read byte by byte
+	for _, r := range []io.Reader{bufio.NewReader(bytes.NewReader(buf.Bytes())), struct{ io.Reader }{bytes.NewReader(buf.Bytes())}} {
+		g := new([768]int32)
+		k := new([1536]int32)
+		if err := ReadPoly(r, g); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReadResult(r, k); err != nil {
+			t.Fatal(err)
+		}
+		if *g != *f || *k != *h {
+			t.Fatal("read back different polynomials")
+		}
+		if err := ReadPoly(r, g); err != nil {
+			t.Fatal(err)
+		}
+		if err := ReadPoly(r, g); err != io.EOF {
+			t.Fatalf("err=%v, want io.EOF", err)
+		}
+	}
+
+	g := new([768]int32)
+	g[0] = 1
+	if err := ReadPoly(bytes.NewReader([]byte("2, 9829\n")), g); err == nil {
+		t.Fatal("coefficient 9829 was accepted")
+	}
+	if g[0] != 1 {
+		t.Fatal("g modified on error")
+	}
+	if err := ReadPoly(bytes.NewReader([]byte("2, 3")), g); err != nil || g[0] != 2 || g[1] != 3 {
+		t.Fatalf("unterminated line: err=%v", err)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type message struct {
+		Key *JSONPoly768 `json:"key"`
+	}
+	f := randPoly(new([768]int32))
+	data, err := json.Marshal(message{Key: (*JSONPoly768)(f)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte(`{"key":[`+strconv.Itoa(int(f[0]))+",")) {
+		t.Fatalf("unexpected encoding %.40s...", data)
+	}
+	var m message
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if *m.Key.Poly() != *f {
+		t.Fatal("JSON round trip changed the polynomial")
+	}
+}
+
+func TestJSONRejectsBadLength(t *testing.T) {
+	for _, n := range []int{0, 767, 769} {
+		data := []byte("[" + strings.TrimSuffix(strings.Repeat("1,", n), ",") + "]")
+		p := new(JSONPoly768)
+		if err := json.Unmarshal(data, p); err == nil {
+			t.Fatalf("accepted %d coefficients", n)
+		}
+		if *p != (JSONPoly768{}) {
+			t.Fatal("p modified on error")
+		}
+	}
+	if err := json.Unmarshal([]byte(`"1, 2"`), new(JSONPoly768)); err == nil {
+		t.Fatal("accepted a string")
+	}
+}
+
+func TestJSONRejectsOutOfRange(t *testing.T) {
+	for _, x := range []string{"-1", "9829", "4294967297", "1.5"} {
+		data := []byte("[" + strings.Repeat("1,", 767) + x + "]")
+		p := new(JSONPoly768)
+		if err := json.Unmarshal(data, p); err == nil {
+			t.Fatalf("accepted coefficient %s", x)
+		}
+		if *p != (JSONPoly768{}) {
+			t.Fatal("p modified on error")
+		}
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	p := (*Poly768)(randPoly(new([768]int32)))
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1348 || !bytes.Equal(data[:4], []byte{1, 0, 0x65, 0x26}) {
+		t.Fatalf("unexpected header or length: % x, %d", data[:4], len(data))
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		t.Fatal(err)
+	}
+	q := new(Poly768)
+	if err := gob.NewDecoder(&buf).Decode(q); err != nil {
+		t.Fatal(err)
+	}
+	if *q != *p {
+		t.Fatal("gob round trip changed the polynomial")
+	}
+
+	p[5] = 9829
+	if _, err := p.MarshalBinary(); err == nil {
+		t.Fatal("MarshalBinary accepted coefficient 9829")
+	}
+}
+
+func TestBinaryRejectsWrongLength(t *testing.T) {
+	p := (*Poly768)(randPoly(new([768]int32)))
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := [][]byte{
+		nil,
+		data[:1347],
+		append(data[:1348:1348], 0),
+		append([]byte{2, 0, 0x65, 0x26}, data[4:]...),
+		append([]byte{1, 0, 0xef, 0x11}, data[4:]...),
+	}
+	for i, b := range bad {
+		q := new(Poly768)
+		if err := q.UnmarshalBinary(b); err == nil {
+			t.Fatalf("case %d: accepted", i)
+		}
+		if *q != (Poly768{}) {
+			t.Fatalf("case %d: q modified on error", i)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// degree returns the degree of a, whose coefficients must be reduced, or -1
+// if a is zero. Its running time depends on a.
+func degree(a []int32) int {
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] != 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// Degree returns the degree of f, the index of its highest nonzero
+// coefficient, or -1 if f is zero. Like degree, which it wraps, its running
+// time depends on f, so it is meant for polynomials whose degree is public.
+func Degree(f *[768]int32) int {
+	return degree(f[:])
+}
+
+// invertPoly sets out to the inverse of f modulo r over GF(q), where r has
+// degree len(r)-1 and f has lower degree, and reports whether the inverse
+// exists. It runs the extended Euclidean algorithm, keeping track of the
+// Bezout coefficient of f only. Its running time depends on the degrees of
+// the remainders, and therefore on f.
+func invertPoly(out, f, r []int32, z zq) bool {
+	n := len(r) - 1
+	r0 := make([]int32, n+1)
+	r1 := make([]int32, n+1)
+	s0 := make([]int32, n+1)
+	s1 := make([]int32, n+1)
+	for i := range r {
+		r0[i] = z.freeze(r[i])
+	}
+	for i := range f {
+		r1[i] = z.freeze(f[i])
+	}
+	s1[0] = 1
+
+	// invariant: r0 = s0*f and r1 = s1*f modulo r
+	d0, d1 := degree(r0), degree(r1)
+	for d1 > 0 {
+		inv := z.inverse(r1[d1])
+		for d0 >= d1 {
+			c := z.freeze(r0[d0] * inv)
+			k := d0 - d1
+			for i := 0; i <= d1; i++ {
+				r0[i+k] = z.freeze(r0[i+k] - c*r1[i])
+			}
+			for i := 0; i+k <= n; i++ {
+				s0[i+k] = z.freeze(s0[i+k] - c*s1[i])
+			}
+			d0 = degree(r0[:d0])
+		}
+		r0, r1 = r1, r0
+		s0, s1 = s1, s0
+		d0, d1 = d1, d0
+	}
+	if d1 < 0 {
+		return false
+	}
+
+	inv := z.inverse(r1[0])
+	for i := range out {
+		out[i] = z.freeze(inv * s1[i])
+	}
+	return true
+}
+
+// inverseTable holds the inverse of every element of Z_9829, with
+// inverseTable[0] = 0. It is filled by the recurrence
+// 1/i = -(9829/i) * 1/(9829 mod i), which takes a single pass.
+var inverseTable = func() *[9829]int32 {
+	t := new([9829]int32)
+	t[1] = 1
+	for i := int32(2); i < 9829; i++ {
+		t[i] = Freeze(-(9829 / i) * t[9829%i])
+	}
+	return t
+}()
+
+// GCD sets out to the monic greatest common divisor over Z_9829 of f and g,
+// of degrees degf and degg, and returns its degree. The coefficients of f
+// and g above their degrees are ignored; a negative degree denotes the zero
+// polynomial. degf and degg must be at most 768, the largest degree that fits
+// in f and g; GCD panics otherwise. If f and g are both zero, out is zeroed
+// and GCD returns -1. Its running time depends on f and g.
+func GCD(out *[769]int32, f, g *[769]int32, degf, degg int) int {
+	if degf > 768 || degg > 768 {
+		panic("karatsuba768: degree out of range")
+	}
+	var a, b [769]int32
+	for i := 0; i <= degf; i++ {
+		a[i] = Freeze(f[i])
+	}
+	for i := 0; i <= degg; i++ {
+		b[i] = Freeze(g[i])
+	}
+	r0, r1 := a[:], b[:]
+	d0, d1 := degree(r0), degree(r1)
+	for d1 >= 0 {
+		inv := inverseTable[r1[d1]]
+		for d0 >= d1 {
+			c := Freeze(r0[d0] * inv)
+			k := d0 - d1
+			for i := 0; i <= d1; i++ {
+				r0[i+k] = Freeze(r0[i+k] - c*r1[i])
+			}
+			d0 = degree(r0[:d0])
+		}
+		r0, r1 = r1, r0
+		d0, d1 = d1, d0
+	}
+
+	*out = [769]int32{}
+	if d0 < 0 {
+		return -1
+	}
+	inv := inverseTable[r0[d0]]
+	for i := 0; i <= d0; i++ {
+		out[i] = Freeze(r0[i] * inv)
+	}
+	return d0
+}
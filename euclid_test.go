@@ -0,0 +1,142 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDegreeKnownPolys(t *testing.T) {
+	f := new([768]int32)
+	if d := Degree(f); d != -1 {
+		t.Fatalf("degree of zero is %d, want -1", d)
+	}
+	f[0] = 5
+	if d := Degree(f); d != 0 {
+		t.Fatalf("degree of 5 is %d, want 0", d)
+	}
+	f[1] = 9828
+	if d := Degree(f); d != 1 {
+		t.Fatalf("degree of 5 - x is %d, want 1", d)
+	}
+	f[767] = 1
+	if d := Degree(f); d != 767 {
+		t.Fatalf("degree with x^767 is %d, want 767", d)
+	}
+	f[0], f[1] = 0, 0
+	if d := Degree(f); d != 767 {
+		t.Fatalf("degree of x^767 is %d, want 767", d)
+	}
+}
+
+func TestInverseTable(t *testing.T) {
+	for x := int32(1); x < 9829; x++ {
+		if y := Freeze(x * inverseTable[x]); y != 1 {
+			t.Fatalf("x*inverseTable[x]=%d for x=%d", y, x)
+		}
+	}
+}
+
+// mulGCD sets h to f*g over Z_9829, where f and g have degrees df and dg.
+func mulGCD(h, f, g *[769]int32, df, dg int) {
+	*h = [769]int32{}
+	for i := 0; i <= df; i++ {
+		for j := 0; j <= dg; j++ {
+			h[i+j] = Freeze(h[i+j] + f[i]*g[j])
+		}
+	}
+}
+
+func TestGCDKnownPairs(t *testing.T) {
+	tests := []struct {
+		f, g, want []int32
+	}{
+		// x^2 - 1 and x - 1
+		{[]int32{-1, 0, 1}, []int32{-1, 1}, []int32{9828, 1}},
+		// x^2 + 2x + 1 and x^2 - 1
+		{[]int32{1, 2, 1}, []int32{-1, 0, 1}, []int32{1, 1}},
+		// x^4 - 1 and x^6 - 1
+		{[]int32{-1, 0, 0, 0, 1}, []int32{-1, 0, 0, 0, 0, 0, 1}, []int32{9828, 0, 1}},
+		// 3x + 6 and 5x^2 + 10x
+		{[]int32{6, 3}, []int32{0, 10, 5}, []int32{2, 1}},
+		// x^761 - x - 1 and x
+		{append([]int32{-1, -1}, append(make([]int32, 759), 1)...), []int32{0, 1}, []int32{1}},
+		// 7 and 0
+		{[]int32{7}, nil, []int32{1}},
+		// 0 and x^3
+		{nil, []int32{0, 0, 0, 1}, []int32{0, 0, 0, 1}},
+		// 0 and 0
+		{nil, nil, nil},
+	}
+	for i, tt := range tests {
+		f, g := new([769]int32), new([769]int32)
+		copy(f[:], tt.f)
+		copy(g[:], tt.g)
+		out := new([769]int32)
+		if d := GCD(out, f, g, len(tt.f)-1, len(tt.g)-1); d != len(tt.want)-1 {
+			t.Fatalf("test %d: degree %d != %d", i, d, len(tt.want)-1)
+		}
+		want := new([769]int32)
+		copy(want[:], tt.want)
+		if *out != *want {
+			t.Fatalf("test %d: gcd=%v != %v", i, out[:len(tt.want)], tt.want)
+		}
+	}
+}
+
+func TestGCDDegreeBound(t *testing.T) {
+	// x^768 - 1 and x^2 - 1, both of the largest degree accepted
+	f, g := new([769]int32), new([769]int32)
+	f[0], f[768] = -1, 1
+	g[0], g[2] = -1, 1
+	out := new([769]int32)
+	if d := GCD(out, f, g, 768, 2); d != 2 || out[0] != 9828 || out[2] != 1 {
+		t.Fatalf("gcd of degree %d: %v", d, out[:3])
+	}
+	if d := GCD(out, g, f, 2, 768); d != 2 {
+		t.Fatalf("gcd of degree %d with swapped arguments", d)
+	}
+
+	for _, deg := range [][2]int{{769, 2}, {2, 769}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("GCD accepted degrees %v", deg)
+				}
+			}()
+			GCD(out, f, g, deg[0], deg[1])
+		}()
+	}
+}
+
+func TestGCDCommonFactor(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		a, b, d := new([769]int32), new([769]int32), new([769]int32)
+		for j := 0; j < 300; j++ {
+			a[j] = int32(rand.Intn(9829))
+			b[j] = int32(rand.Intn(9829))
+		}
+		a[300], b[250] = 1, 1
+		for j := 0; j < 100; j++ {
+			d[j] = int32(rand.Intn(9829))
+		}
+		d[100] = 1
+		out := new([769]int32)
+		if GCD(out, a, b, 300, 250) != 0 {
+			// a and b are coprime with probability 1 - 1/9829
+			continue
+		}
+		f, g := new([769]int32), new([769]int32)
+		mulGCD(f, a, d, 300, 100)
+		mulGCD(g, b, d, 250, 100)
+		if n := GCD(out, f, g, 400, 350); n != 100 || *out != *d {
+			t.Fatalf("gcd of degree %d != d", n)
+		}
+		if n := GCD(out, g, f, 350, 400); n != 100 || *out != *d {
+			t.Fatalf("gcd of degree %d != d with swapped arguments", n)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768_test
+
+import (
+	"fmt"
+
+	"github.com/martelletto/karatsuba768"
+)
+
+// This example computes h*r in Z_9829[x]/(x^761-x-1), as done when
+// encrypting in NTRU Prime, using h = x^760 + 1 and r = x^2 + 1. The inputs
+// are padded with zeros to 768 coefficients, and the 1536 coefficient
+// product is then reduced using x^761 = x + 1.
+func ExampleMul() {
+	h := new([768]int32)
+	r := new([768]int32)
+	h[760], h[0] = 1, 1
+	r[2], r[0] = 1, 1
+
+	c := new([1536]int32)
+	karatsuba768.Mul(c, h, r)
+	for i := 1520; i >= 761; i-- {
+		c[i-761] = karatsuba768.Freeze(c[i-761] + c[i])
+		c[i-760] = karatsuba768.Freeze(c[i-760] + c[i])
+		c[i] = 0
+	}
+
+	for i := 0; i < 761; i++ {
+		if c[i] != 0 {
+			fmt.Printf("%d*x^%d\n", c[i], i)
+		}
+	}
+	// Output:
+	// 1*x^0
+	// 1*x^1
+	// 2*x^2
+	// 1*x^760
+}
+
+func ExampleFreeze() {
+	fmt.Println(karatsuba768.Freeze(-1))
+	fmt.Println(karatsuba768.Freeze(9829 * 3))
+	fmt.Println(karatsuba768.Freeze(165191049))
+	// Output:
+	// 9828
+	// 0
+	// 4875
+}
+
+// This example computes a cyclic convolution, in which the coefficients of
+// the product that exceed x^767 wrap around to the lowest degrees.
+func ExampleCyclicMul() {
+	f := new([768]int32)
+	g := new([768]int32)
+	f[767], f[0] = 1, 2
+	g[1] = 3
+
+	h := new([768]int32)
+	karatsuba768.CyclicMul(h, f, g)
+	for i := range h {
+		if h[i] != 0 {
+			fmt.Printf("%d*x^%d\n", h[i], i)
+		}
+	}
+	// Output:
+	// 3*x^0
+	// 6*x^1
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+package karatsuba768
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+// freezeAsm is Freeze, using a conditional move for the final correction.
+// Freeze itself does not call it: the compiler inlines Freeze, along with its
+// calls to crypto/subtle, into the multiplication loops, which an assembly
+// function prevents, and Mul is about 10% slower with freezeAsm. It is kept
+// to check the code generated for Freeze against, see TestFreezeAsm and
+// BenchmarkFreezeScalarAsm.
+//
+//go:noescape
+func freezeAsm(x int32) int32
+
+// freezeSSE2Asm and freezeAVX2Asm apply Freeze to n elements starting at p,
+// four or eight at a time. n must be a multiple of 4 or 8 respectively.
+
+//go:noescape
+func freezeSSE2Asm(p *int32, n int)
+
+//go:noescape
+func freezeAVX2Asm(p *int32, n int)
+
+// hasAVX2 is set if both the CPU and the operating system support AVX2.
+var hasAVX2 bool
+
+func init() {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID >= 7 {
+		_, _, ecx1, _ := cpuid(1, 0)
+		_, ebx7, _, _ := cpuid(7, 0)
+		osxsave := ecx1&(1<<27) != 0
+		avx := ecx1&(1<<28) != 0
+		if osxsave && avx {
+			xcr0, _ := xgetbv()
+			hasAVX2 = xcr0&6 == 6 && ebx7&(1<<5) != 0
+		}
+	}
+
+	if debugBuild || montgomeryBuild {
+		return
+	}
+
+	// SSE2 is part of the amd64 baseline.
+	freezeSlice = freezeSSE2
+	if hasAVX2 {
+		freezeSlice = freezeAVX2
+		x4MulLeaf = x4MulAVX2
+		karatsuba5SquareLeaf = karatsuba5SquareAVX2
+	}
+}
+
+func freezeSSE2(p []int32) {
+	n := len(p) &^ 3
+	if n > 0 {
+		freezeSSE2Asm(&p[0], n)
+	}
+	freezeGeneric(p[n:])
+}
+
+func freezeAVX2(p []int32) {
+	n := len(p) &^ 7
+	if n > 0 {
+		freezeAVX2Asm(&p[0], n)
+	}
+	freezeGeneric(p[n:])
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+DATA freeze13<>+0x00(SB)/8, $0x0000000d0000000d
+DATA freeze13<>+0x08(SB)/8, $0x0000000d0000000d
+DATA freeze13<>+0x10(SB)/8, $0x0000000d0000000d
+DATA freeze13<>+0x18(SB)/8, $0x0000000d0000000d
+GLOBL freeze13<>(SB), RODATA|NOPTR, $32
+
+DATA freeze427<>+0x00(SB)/8, $0x000001ab000001ab
+DATA freeze427<>+0x08(SB)/8, $0x000001ab000001ab
+DATA freeze427<>+0x10(SB)/8, $0x000001ab000001ab
+DATA freeze427<>+0x18(SB)/8, $0x000001ab000001ab
+GLOBL freeze427<>(SB), RODATA|NOPTR, $32
+
+DATA freeze9829<>+0x00(SB)/8, $0x0000266500002665
+DATA freeze9829<>+0x08(SB)/8, $0x0000266500002665
+DATA freeze9829<>+0x10(SB)/8, $0x0000266500002665
+DATA freeze9829<>+0x18(SB)/8, $0x0000266500002665
+GLOBL freeze9829<>(SB), RODATA|NOPTR, $32
+
+DATA freezeRound<>+0x00(SB)/8, $0x0020000000200000
+DATA freezeRound<>+0x08(SB)/8, $0x0020000000200000
+DATA freezeRound<>+0x10(SB)/8, $0x0020000000200000
+DATA freezeRound<>+0x18(SB)/8, $0x0020000000200000
+GLOBL freezeRound<>(SB), RODATA|NOPTR, $32
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
+
+// func freezeAsm(x int32) int32
+TEXT ·freezeAsm(SB), NOSPLIT, $0-12
+	MOVL x+0(FP), AX
+
+	// x -= 9829 * ((13*x) >> 17)
+	IMUL3L $13, AX, CX
+	SARL $17, CX
+	IMUL3L $9829, CX, CX
+	SUBL CX, AX
+
+	// x -= 9829 * ((427*x + 2097152) >> 22)
+	IMUL3L $427, AX, CX
+	ADDL $2097152, CX
+	SARL $22, CX
+	IMUL3L $9829, CX, CX
+	SUBL CX, AX
+
+	// x += 9829 if x < 0, without a branch
+	LEAL 9829(AX), CX
+	TESTL AX, AX
+	CMOVLLT CX, AX
+
+	MOVL AX, ret+8(FP)
+	RET
+
+// MUL9829 sets dst to 9829*src, computed as the sum of src shifted by the
+// bits of 9829 = 2^13 + 2^10 + 2^9 + 2^6 + 2^5 + 2^2 + 2^0, since SSE2 has no
+// 32-bit multiplication. tmp is clobbered.
+#define MUL9829(src, dst, tmp) \
+	MOVO src, dst \
+	MOVO src, tmp \
+	PSLLL $2, tmp \
+	PADDL tmp, dst \
+	MOVO src, tmp \
+	PSLLL $5, tmp \
+	PADDL tmp, dst \
+	MOVO src, tmp \
+	PSLLL $6, tmp \
+	PADDL tmp, dst \
+	MOVO src, tmp \
+	PSLLL $9, tmp \
+	PADDL tmp, dst \
+	MOVO src, tmp \
+	PSLLL $10, tmp \
+	PADDL tmp, dst \
+	MOVO src, tmp \
+	PSLLL $13, tmp \
+	PADDL tmp, dst
+
+// func freezeSSE2Asm(p *int32, n int)
+TEXT ·freezeSSE2Asm(SB), NOSPLIT, $0-16
+	MOVQ p+0(FP), DI
+	MOVQ n+8(FP), CX
+	MOVOU freeze9829<>(SB), X6
+	MOVOU freezeRound<>(SB), X7
+	TESTQ CX, CX
+	JZ    sse2done
+
+sse2loop:
+	MOVOU (DI), X0
+
+	// x -= 9829 * ((13*x) >> 17), with 13 = 2^3 + 2^2 + 2^0
+	MOVO  X0, X1
+	MOVO  X0, X2
+	PSLLL $3, X1
+	PSLLL $2, X2
+	PADDL X2, X1
+	PADDL X0, X1
+	PSRAL $17, X1
+	MUL9829(X1, X2, X3)
+	PSUBL X2, X0
+
+	// x -= 9829 * ((427*x + 2097152) >> 22), with
+	// 427 = 2^8 + 2^7 + 2^5 + 2^3 + 2^1 + 2^0
+	MOVO  X0, X1
+	MOVO  X0, X2
+	PSLLL $1, X2
+	PADDL X2, X1
+	MOVO  X0, X2
+	PSLLL $3, X2
+	PADDL X2, X1
+	MOVO  X0, X2
+	PSLLL $5, X2
+	PADDL X2, X1
+	MOVO  X0, X2
+	PSLLL $7, X2
+	PADDL X2, X1
+	MOVO  X0, X2
+	PSLLL $8, X2
+	PADDL X2, X1
+	PADDL X7, X1
+	PSRAL $22, X1
+	MUL9829(X1, X2, X3)
+	PSUBL X2, X0
+
+	// x += 9829 if x < 0
+	MOVO  X0, X1
+	PSRAL $31, X1
+	PAND  X6, X1
+	PADDL X1, X0
+
+	MOVOU X0, (DI)
+	ADDQ  $16, DI
+	SUBQ  $4, CX
+	JNZ   sse2loop
+
+sse2done:
+	RET
+
+// func freezeAVX2Asm(p *int32, n int)
+TEXT ·freezeAVX2Asm(SB), NOSPLIT, $0-16
+	MOVQ    p+0(FP), DI
+	MOVQ    n+8(FP), CX
+	VMOVDQU freeze13<>(SB), Y1
+	VMOVDQU freeze427<>(SB), Y2
+	VMOVDQU freeze9829<>(SB), Y3
+	VMOVDQU freezeRound<>(SB), Y5
+	TESTQ   CX, CX
+	JZ      avx2done
+
+avx2loop:
+	VMOVDQU (DI), Y0
+
+	// x -= 9829 * ((13*x) >> 17)
+	VPMULLD Y1, Y0, Y4
+	VPSRAD  $17, Y4, Y4
+	VPMULLD Y3, Y4, Y4
+	VPSUBD  Y4, Y0, Y0
+
+	// x -= 9829 * ((427*x + 2097152) >> 22)
+	VPMULLD Y2, Y0, Y4
+	VPADDD  Y5, Y4, Y4
+	VPSRAD  $22, Y4, Y4
+	VPMULLD Y3, Y4, Y4
+	VPSUBD  Y4, Y0, Y0
+
+	// x += 9829 if x < 0
+	VPSRAD $31, Y0, Y4
+	VPAND  Y3, Y4, Y4
+	VPADDD Y4, Y0, Y0
+
+	VMOVDQU Y0, (DI)
+	ADDQ    $32, DI
+	SUBQ    $8, CX
+	JNZ     avx2loop
+
+avx2done:
+	VZEROUPPER
+	RET
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFreezeSlice(t *testing.T) {
+	impls := map[string]func([]int32){
+		"generic": freezeGeneric,
+		"sse2":    freezeSSE2,
+	}
+	if hasAVX2 {
+		impls["avx2"] = freezeAVX2
+	} else {
+		t.Log("AVX2 not supported, skipping")
+	}
+
+	// boundaries and a tail that is not a multiple of the vector width
+	p := []int32{0, 1, -1, 9828, 9829, -9829, 165191049, -165191049}
+	for i := 0; i < 1<<16+5; i++ {
+		p = append(p, int32(rand.Intn(2*165191049+1)-165191049))
+	}
+	want := make([]int32, len(p))
+	for i := range p {
+		want[i] = Freeze(p[i])
+	}
+	for name, freeze := range impls {
+		for _, n := range []int{0, 1, 3, 4, 7, 8, 9, len(p)} {
+			got := append([]int32(nil), p[:n]...)
+			freeze(got)
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("%s: got %d, want %d for x=%d", name, got[i], want[i], p[i])
+				}
+			}
+		}
+	}
+}
+
+func TestFreezeSliceExhaustive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping exhaustive test in short mode")
+	}
+	const n = 1 << 20
+	p := make([]int32, n)
+	q := make([]int32, n)
+	for x := int64(-165191049); x < 165191050; x += n {
+		for i := range p {
+			p[i] = int32(x + int64(i))
+			if p[i] > 165191049 {
+				p[i] = 165191049
+			}
+		}
+		copy(q, p)
+		freezeSlice(q)
+		for i := range q {
+			if q[i] != Freeze(p[i]) {
+				t.Fatalf("got %d, want %d for x=%d", q[i], Freeze(p[i]), p[i])
+			}
+		}
+	}
+}
+
+func BenchmarkFreezeSliceGeneric(b *testing.B) { benchmarkFreezeSlice(b, freezeGeneric) }
+func BenchmarkFreezeSliceSSE2(b *testing.B)    { benchmarkFreezeSlice(b, freezeSSE2) }
+
+func BenchmarkFreezeSliceAVX2(b *testing.B) {
+	if !hasAVX2 {
+		b.Skip("AVX2 not supported")
+	}
+	benchmarkFreezeSlice(b, freezeAVX2)
+}
+
+func benchmarkFreezeSlice(b *testing.B, freeze func([]int32)) {
+	p := make([]int32, 256)
+	for i := 0; i < b.N; i++ {
+		freeze(p)
+	}
+}
+
+func TestFreezeAsm(t *testing.T) {
+	step := int64(1)
+	if testing.Short() {
+		step = 9829*7 + 1
+	}
+	for x := int64(-165191049); x < 165191050; x += step {
+		if y, z := freezeAsm(int32(x)), Freeze(int32(x)); y != z {
+			t.Fatalf("freezeAsm(%d)=%d != Freeze(%d)=%d", x, y, x, z)
+		}
+	}
+	for _, x := range []int32{-165191049, -1, 0, 9828, 9829, 165191049} {
+		if y, z := freezeAsm(x), Freeze(x); y != z {
+			t.Fatalf("freezeAsm(%d)=%d != Freeze(%d)=%d", x, y, x, z)
+		}
+	}
+}
+
+var freezeSink int32
+
+func BenchmarkFreezeScalarGo(b *testing.B) {
+	x := int32(0)
+	for i := 0; i < b.N; i++ {
+		x = Freeze(x + 123457)
+	}
+	freezeSink = x
+}
+
+func BenchmarkFreezeScalarAsm(b *testing.B) {
+	x := int32(0)
+	for i := 0; i < b.N; i++ {
+		x = freezeAsm(x + 123457)
+	}
+	freezeSink = x
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Coeff is the set of integer types that can hold a coefficient in [0, 9828].
+type Coeff interface {
+	~int16 | ~int32
+}
+
+// FreezeGeneric reduces x modulo 9829. Every int16 lies in the domain of
+// Freeze, so the reduction is done by widening x to int32.
+func FreezeGeneric[T Coeff](x T) T {
+	return T(Freeze(int32(x)))
+}
+
+// MulGeneric is like Mul for polynomials stored with any coefficient type in
+// Coeff. The intermediate values of the Karatsuba and Toom layers, such as the
+// products of two coefficients, do not fit in 16 bits, so the arithmetic is
+// always done in int32: the inputs are widened, multiplied by Mul, and the
+// result, whose coefficients are in [0, 9828], is narrowed back to T.
+func MulGeneric[T Coeff](h *[1536]T, f, g *[768]T) {
+	a := new([768]int32)
+	b := new([768]int32)
+	c := new([1536]int32)
+	for i := range f {
+		a[i] = int32(f[i])
+		b[i] = int32(g[i])
+	}
+	Mul(c, a, b)
+	for i := range c {
+		h[i] = T(c[i])
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestFreezeGeneric(t *testing.T) {
+	for i := -32768; i < 32768; i++ {
+		x := FreezeGeneric(int16(i))
+		y := FreezeGeneric(int32(i))
+		if int32(x) != y || y != Freeze(int32(i)) {
+			t.Fatalf("x=%d, y=%d for i=%d", x, y, i)
+		}
+	}
+}
+
+func TestMulGeneric(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		a16 := new([768]int16)
+		b16 := new([768]int16)
+		for j := range a {
+			a16[j] = int16(a[j])
+			b16[j] = int16(b[j])
+		}
+		c := new([1536]int32)
+		d := new([1536]int32)
+		d16 := new([1536]int16)
+		Mul(c, a, b)
+		MulGeneric(d, a, b)
+		MulGeneric(d16, a16, b16)
+		for j := range c {
+			if c[j] != d[j] || c[j] != int32(d16[j]) {
+				t.Fatalf("c=%d, d=%d, d16=%d for j=%d", c[j], d[j], d16[j], j)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Karatsuba384 implements 384n x 384n by splitting f and g into three blocks
+// of 128 coefficients and computing the six products of three-way Karatsuba
+// with Karatsuba1. The product has 767 coefficients; p must have room for
+// 768, the last of which is set to zero.
+func (p thinPoly) Karatsuba384(f, g thinPoly) thinPoly {
+	var a = make(thinPoly, 128)
+	var b = make(thinPoly, 128)
+	var t = make(thinPoly, 256)
+	var ws = make(thinPoly, karatsuba1Workspace)
+	f0, f1, f2 := f[:128], f[128:256], f[256:384]
+	g0, g1, g2 := g[:128], g[128:256], g[256:384]
+
+	p00 := make(thinPoly, 256).Karatsuba1(f0, g0, ws)
+	p11 := make(thinPoly, 256).Karatsuba1(f1, g1, ws)
+	p22 := make(thinPoly, 256).Karatsuba1(f2, g2, ws)
+	p01 := make(thinPoly, 256).Karatsuba1(a.Add(f0, f1), b.Add(g0, g1), ws)
+	p02 := make(thinPoly, 256).Karatsuba1(a.Add(f0, f2), b.Add(g0, g2), ws)
+	p12 := make(thinPoly, 256).Karatsuba1(a.Add(f1, f2), b.Add(g1, g2), ws)
+
+	p[:768].Zero()
+	p.Inc(p00)
+	p[128:].Inc(p01)
+	p[128:].Inc(t.Mul(-1, p00))
+	p[128:].Inc(t.Mul(-1, p11))
+	p[256:].Inc(p02)
+	p[256:].Inc(t.Mul(-1, p00))
+	p[256:].Inc(t.Mul(-1, p22))
+	p[256:].Inc(p11)
+	p[384:].Inc(p12)
+	p[384:].Inc(t.Mul(-1, p11))
+	p[384:].Inc(t.Mul(-1, p22))
+	p[512:].Inc(p22)
+
+	return p[:768].Freeze()
+}
+
+// MulKaratsuba2Way computes the same product as Mul, but splits f and g in
+// halves of 384 coefficients and applies two-way Karatsuba at the top level,
+// with Karatsuba384 for the three half-size products, instead of Toom6. It
+// takes eighteen 128n x 128n products where Toom6 takes eleven, and is
+// provided as an alternative decomposition for comparison.
+func MulKaratsuba2Way(h *[1535]int32, f, g *[768]int32) {
+	var a = make(thinPoly, 384)
+	var b = make(thinPoly, 384)
+	var t = make(thinPoly, 768)
+	var z = make(thinPoly, 1536)
+	f0, f1 := f[:384], f[384:]
+	g0, g1 := g[:384], g[384:]
+
+	lo := make(thinPoly, 768).Karatsuba384(f0, g0)
+	hi := make(thinPoly, 768).Karatsuba384(f1, g1)
+	mid := make(thinPoly, 768).Karatsuba384(a.Add(f0, f1), b.Add(g0, g1))
+
+	z.Inc(lo)
+	z[384:].Inc(mid)
+	z[384:].Inc(t.Mul(-1, lo))
+	z[384:].Inc(t.Mul(-1, hi))
+	z[768:].Inc(hi)
+	copy(h[:], z.Freeze())
+}
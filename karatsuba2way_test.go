@@ -0,0 +1,30 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestMulKaratsuba2Way(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1535]int32)
+		textbookMul(c, a, b)
+		MulKaratsuba2Way(d, a, b)
+		if [1535]int32(c[:1535]) != *d {
+			t.Fatalf("c != d for i=%d", i)
+		}
+	}
+}
+
+func BenchmarkMulKaratsuba2Way(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1535]int32)
+	for i := 0; i < b.N; i++ {
+		MulKaratsuba2Way(h, f, g)
+	}
+}
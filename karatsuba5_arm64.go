@@ -0,0 +1,28 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build arm64 && !purego
+
+package karatsuba768
+
+// karatsuba5NEON sets p[0:16] to a[0:8]*b[0:8], computed exactly as
+// karatsuba5Generic computes it. NEON is part of the arm64 baseline, so no
+// CPU detection is needed.
+//
+//go:noescape
+func karatsuba5NEON(p, a, b *int32)
+
+func init() {
+	if debugBuild || montgomeryBuild {
+		return
+	}
+	karatsuba5Leaf = karatsuba5Asm
+}
+
+// karatsuba5Asm is Karatsuba5 on top of karatsuba5NEON. It needs no
+// workspace.
+func karatsuba5Asm(p, f, g, _ thinPoly) {
+	_, _, _ = p[15], f[7], g[7]
+	karatsuba5NEON(&p[0], &f[0], &g[0])
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build arm64 && !purego
+
+#include "textflag.h"
+
+// Register use:
+//
+//	V0-V3    f0, f1, g0, g1
+//	V4, V5   the operands of X4MUL
+//	V6, V7   the low and high halves of the product computed by X4MUL
+//	V8-V11   scratch
+//	V14-V17  f0*g0 and the partial sums z1 and z2
+//	V20-V24  the constants 13, 427, 9829, 2^21 and 0
+//	V28-V31  the result, stored with a single VST1
+
+// FREEZE applies Freeze to the four lanes of V9, clobbering V11.
+#define FREEZE \
+	VMUL  V20.S4, V9.S4, V11.S4; \
+	VSSHR $17, V11.S4, V11.S4; \
+	VMUL  V22.S4, V11.S4, V11.S4; \
+	VSUB  V11.S4, V9.S4, V9.S4; \
+	VMUL  V21.S4, V9.S4, V11.S4; \
+	VADD  V23.S4, V11.S4, V11.S4; \
+	VSSHR $22, V11.S4, V11.S4; \
+	VMUL  V22.S4, V11.S4, V11.S4; \
+	VSUB  V11.S4, V9.S4, V9.S4; \
+	VSSHR $31, V9.S4, V11.S4; \
+	VAND  V22.B16, V11.B16, V11.B16; \
+	VADD  V11.S4, V9.S4, V9.S4
+
+// NEGFREEZE sets V9 to Freeze(-x) for the four lanes of x.
+#define NEGFREEZE(x) \
+	VNEG x, V9.S4; \
+	FREEZE
+
+// ROW adds the frozen products V4*V5[j] to V6:V7 shifted up by j lanes, with
+// VEXT moving the four products across the two halves of the result.
+#define ROW(j, shift) \
+	VDUP  V5.S[j], V8.S4; \
+	VMUL  V8.S4, V4.S4, V9.S4; \
+	FREEZE; \
+	VEXT  shift, V9.B16, V24.B16, V10.B16; \
+	VADD  V10.S4, V6.S4, V6.S4; \
+	VEXT  shift, V24.B16, V9.B16, V10.B16; \
+	VADD  V10.S4, V7.S4, V7.S4
+
+// X4MUL sets V6:V7 to x4Mul(V4, V5).
+#define X4MUL \
+	VDUP  V5.S[0], V8.S4; \
+	VMUL  V8.S4, V4.S4, V9.S4; \
+	FREEZE; \
+	VMOV  V9.B16, V6.B16; \
+	VMOV  V24.B16, V7.B16; \
+	ROW(1, $12); \
+	ROW(2, $8); \
+	ROW(3, $4)
+
+// func karatsuba5NEON(p, a, b *int32)
+//
+// The steps are those of Karatsuba5, with the 16 coefficients of the result
+// and of z held in four vectors each. Since z[12:16] is always zero, only
+// z[0:12] is kept, in V14, V16 and V17.
+TEXT ·karatsuba5NEON(SB), NOSPLIT, $0-24
+	MOVD p+0(FP), R0
+	MOVD a+8(FP), R1
+	MOVD b+16(FP), R2
+
+	MOVW $13, R4
+	VDUP R4, V20.S4
+	MOVW $427, R4
+	VDUP R4, V21.S4
+	MOVW $9829, R4
+	VDUP R4, V22.S4
+	MOVW $2097152, R4
+	VDUP R4, V23.S4
+	VEOR V24.B16, V24.B16, V24.B16
+
+	VLD1 (R1), [V0.S4, V1.S4]
+	VLD1 (R2), [V2.S4, V3.S4]
+
+	// z[0:8] = f0*g0
+	VMOV V0.B16, V4.B16
+	VMOV V2.B16, V5.B16
+	X4MUL
+	VMOV V6.B16, V14.B16
+	VMOV V7.B16, V15.B16
+
+	// z[4:12] += -(f1*g1)
+	VMOV V1.B16, V4.B16
+	VMOV V3.B16, V5.B16
+	X4MUL
+	NEGFREEZE(V6.S4)
+	VADD V9.S4, V15.S4, V16.S4
+	NEGFREEZE(V7.S4)
+	VMOV V9.B16, V17.B16
+
+	// (f0+f1)*(g0+g1)
+	VADD V0.S4, V1.S4, V9.S4
+	FREEZE
+	VMOV V9.B16, V4.B16
+	VADD V2.S4, V3.S4, V9.S4
+	FREEZE
+	VMOV V9.B16, V5.B16
+	X4MUL
+
+	// p = z, p[4:16] += -z[0:12], p[4:12] += (f0+f1)*(g0+g1)
+	VMOV V14.B16, V28.B16
+	NEGFREEZE(V14.S4)
+	VADD V9.S4, V16.S4, V29.S4
+	VADD V6.S4, V29.S4, V29.S4
+	NEGFREEZE(V16.S4)
+	VADD V9.S4, V17.S4, V30.S4
+	VADD V7.S4, V30.S4, V30.S4
+	NEGFREEZE(V17.S4)
+	VMOV V9.B16, V31.B16
+
+	VST1 [V28.S4, V29.S4, V30.S4, V31.S4], (R0)
+	RET
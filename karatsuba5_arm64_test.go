@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build arm64 && !purego
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestKaratsuba5NEON(t *testing.T) {
+	f := make(thinPoly, 8)
+	g := make(thinPoly, 8)
+	p := make(thinPoly, 16)
+	q := make(thinPoly, 16)
+	for i := 0; i < 10000; i++ {
+		for j := range f {
+			f[j] = int32(rand.Intn(9829))
+			g[j] = int32(rand.Intn(9829))
+			if i == 0 {
+				f[j], g[j] = 9828, 9828
+			}
+		}
+		for j := range p {
+			p[j], q[j] = -1, -1
+		}
+		karatsuba5Generic(p, f, g, nil)
+		karatsuba5Asm(q, f, g, nil)
+		for j := range p {
+			if p[j] != q[j] {
+				t.Fatalf("f=%v, g=%v: karatsuba5Asm gives %v, karatsuba5Generic %v", f, g, q, p)
+			}
+		}
+	}
+}
+
+func BenchmarkKaratsuba5NEON(b *testing.B) {
+	f := thinPoly(randPoly(new([768]int32))[:8])
+	g := thinPoly(randPoly(new([768]int32))[:8])
+	p := make(thinPoly, 16)
+	for i := 0; i < b.N; i++ {
+		karatsuba5Asm(p, f, g, nil)
+	}
+}
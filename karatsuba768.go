@@ -7,12 +7,21 @@
 
 package karatsuba768
 
-import "crypto/subtle"
+import (
+	"crypto/subtle"
+	"fmt"
+)
 
 type thinPoly []int32
 
 // Freeze reduces x modulo 9829, for x in (-165191050,+165191050).
+//
+// The bounds are those of 13*x, which overflows an int32 at +-165191050. The
+// result is then not merely off by a multiple of 9829 but far out of range:
+// Freeze(165191050) is 321865310 and Freeze(-165191050) is -321855481, and
+// every input outside the interval gives a result outside [0, 9828].
 func Freeze(x int32) int32 {
+	checkFreeze(x)
 	x -= 9829 * ((13*x) >> 17)
 	x -= 9829 * ((427*x + 2097152) >> 22)
 	y := x + 9829
@@ -20,10 +29,18 @@ func Freeze(x int32) int32 {
 	return int32(subtle.ConstantTimeSelect(v, int(y), int(x)))
 }
 
-func (p thinPoly) Freeze() thinPoly {
+// freezeSlice applies Freeze to every element of p. It is set during init()
+// to the fastest implementation supported by the CPU.
+var freezeSlice = freezeGeneric
+
+func freezeGeneric(p []int32) {
 	for i := range p {
 		p[i] = Freeze(p[i])
 	}
+}
+
+func (p thinPoly) Freeze() thinPoly {
+	freezeSlice(p)
 	return p
 }
 
@@ -43,24 +60,44 @@ func (p thinPoly) Set(x []int32) thinPoly {
 	return p
 }
 
-// Add sets p to the addition a + b.
+// Add sets p to the addition a + b. p may be a or b, since every p[i] is
+// written after a[i] and b[i] are read, but must not otherwise overlap
+// them: with p = a[k:], say, p[i] would overwrite a[i+k] before it is read.
+// With the debug tag, Add panics on such overlaps.
 func (p thinPoly) Add(a, b []int32) thinPoly {
+	checkAlias(p, a)
+	checkAlias(p, b)
 	for i := range a {
 		p[i] = Freeze(a[i] + b[i])
 	}
 	return p
 }
 
-// Inc increments the contents of p by x.
+// Sub sets p to the subtraction a - b.
+func (p thinPoly) Sub(a, b []int32) thinPoly {
+	for i := range a {
+		p[i] = Freeze(a[i] - b[i])
+	}
+	return p
+}
+
+// Inc increments the contents of p by x. The sums are not reduced, so every
+// p[i] + x[i] must stay within (-165191050, +165191050) for a later Freeze to
+// be correct; with the debug tag, Inc panics if it does not.
 func (p thinPoly) Inc(x []int32) thinPoly {
+	checkInc(p, x)
 	for i := range x {
 		p[i] += x[i]
 	}
 	return p
 }
 
-// Mul sets p to the multiplication of the polynomial p by the constant c.
+// Mul sets p to the multiplication of the polynomial v by the constant c,
+// and freezes all of p. The Karatsuba levels negate in place with t.Mul(-1, t),
+// which is safe because p[i] is written after v[i] is read; as with Add, p
+// must not otherwise overlap v, and the debug tag checks it.
 func (p thinPoly) Mul(c int32, v []int32) thinPoly {
+	checkAlias(p, v)
 	for i := range v {
 		p[i] = c * v[i]
 	}
@@ -69,24 +106,63 @@ func (p thinPoly) Mul(c int32, v []int32) thinPoly {
 
 // x4Mul implements 4n x 4n, the lowest level of the multiplication algorithm.
 func (p thinPoly) x4Mul(f, g thinPoly) thinPoly {
+	x4MulLeaf(p, f, g)
+	return p
+}
+
+// x4MulLeaf computes x4Mul. It is set during init() to the fastest
+// implementation supported by the CPU.
+var x4MulLeaf = x4MulGeneric
+
+func x4MulGeneric(p, f, g thinPoly) {
 	p.Zero()
 	for i := 0; i < 4; i++ {
 		for j := 0; j < 4; j++ {
 			p[i+j] += Freeze(f[i] * g[j])
 		}
 	}
-	return p
+}
+
+// Sizes of the workspaces used by each level of the multiplication algorithm.
+// A level uses a temporary of n coefficients and one of 2n coefficients, and
+// passes the rest of its workspace on to the level below.
+const (
+	karatsuba5Workspace = 3*8
+	karatsuba4Workspace = 3*16 + karatsuba5Workspace
+	karatsuba3Workspace = 3*32 + karatsuba4Workspace
+	karatsuba2Workspace = 3*64 + karatsuba3Workspace
+	karatsuba1Workspace = 3*128 + karatsuba2Workspace
+)
+
+// workspace returns the first n coefficients of ws, or a newly allocated
+// workspace of n coefficients if ws is nil.
+func workspace(ws thinPoly, n int) thinPoly {
+	if ws == nil {
+		return make(thinPoly, n)
+	}
+	return ws[:n]
 }
 
 // Karatsuba5 uses x4Mul to implement 8n x 8xn.
-func (p thinPoly) Karatsuba5(f, g thinPoly) thinPoly {
-	var t = make(thinPoly, 8)
-	var z = make(thinPoly, 16)
+// ws must be nil or have room for karatsuba5Workspace coefficients.
+func (p thinPoly) Karatsuba5(f, g, ws thinPoly) thinPoly {
+	karatsuba5Leaf(p, f, g, ws)
+	return p
+}
+
+// karatsuba5Leaf computes Karatsuba5. It is set during init() to an
+// implementation in assembly on CPUs that have one.
+var karatsuba5Leaf = karatsuba5Generic
+
+func karatsuba5Generic(p, f, g, ws thinPoly) {
+	ws = workspace(ws, karatsuba5Workspace)
+	t, z := ws[:8], ws[8:24]
 	f0, f1 := f[:4], f[4:]
 	g0, g1 := g[:4], g[4:]
 
 	t.x4Mul(f0, g0)
 	z.Set(t)
+	z[8:].Zero()
 	t.x4Mul(f1, g1)
 	z[4:].Inc(t.Mul(-1, t))
 
@@ -94,91 +170,118 @@ func (p thinPoly) Karatsuba5(f, g thinPoly) thinPoly {
 	p[4:].Inc(z.Mul(-1, z)[:12])
 	t.x4Mul(z.Add(f0, f1), z[4:].Add(g0, g1))
 	p[4:].Inc(t)
-
-	return p
 }
 
 // Karatsuba4 uses Karatsuba5 to implement 16n x 16n.
-func (p thinPoly) Karatsuba4(f, g thinPoly) thinPoly {
-	var t = make(thinPoly, 16)
-	var z = make(thinPoly, 32)
+// ws must be nil or have room for karatsuba4Workspace coefficients.
+func (p thinPoly) Karatsuba4(f, g, ws thinPoly) thinPoly {
+	ws = workspace(ws, karatsuba4Workspace)
+	t, z, w := ws[:16], ws[16:48], ws[48:]
 	f0, f1 := f[:8], f[8:]
 	g0, g1 := g[:8], g[8:]
 
-	t.Karatsuba5(f0, g0)
+	t.Karatsuba5(f0, g0, w)
 	z.Set(t)
-	t.Karatsuba5(f1, g1)
+	z[16:].Zero()
+	t.Karatsuba5(f1, g1, w)
 	z[8:].Inc(t.Mul(-1, t))
 
 	p.Set(z)
 	p[8:].Inc(z.Mul(-1, z)[:24])
-	t.Karatsuba5(z.Add(f0, f1), z[8:].Add(g0, g1))
+	t.Karatsuba5(z.Add(f0, f1), z[8:].Add(g0, g1), w)
 	p[8:].Inc(t)
 
 	return p
 }
 
 // Karatsuba3 uses Karatsuba4 to implement 32n x 32n.
-func (p thinPoly) Karatsuba3(f, g thinPoly) thinPoly {
-	var t = make(thinPoly, 32)
-	var z = make(thinPoly, 64)
+// ws must be nil or have room for karatsuba3Workspace coefficients.
+func (p thinPoly) Karatsuba3(f, g, ws thinPoly) thinPoly {
+	ws = workspace(ws, karatsuba3Workspace)
+	t, z, w := ws[:32], ws[32:96], ws[96:]
 	f0, f1 := f[:16], f[16:]
 	g0, g1 := g[:16], g[16:]
 
-	t.Karatsuba4(f0, g0)
+	t.Karatsuba4(f0, g0, w)
 	z.Set(t)
-	t.Karatsuba4(f1, g1)
+	z[32:].Zero()
+	t.Karatsuba4(f1, g1, w)
 	z[16:].Inc(t.Mul(-1, t))
 
 	p.Set(z)
 	p[16:].Inc(z.Mul(-1, z)[:48])
-	t.Karatsuba4(z.Add(f0, f1), z[16:].Add(g0, g1))
+	t.Karatsuba4(z.Add(f0, f1), z[16:].Add(g0, g1), w)
 	p[16:].Inc(t)
 
 	return p
 }
 
 // Karatsuba2 uses Karatsuba3 to implement 64n x 64n.
-func (p thinPoly) Karatsuba2(f, g thinPoly) thinPoly {
-	var t = make(thinPoly, 64)
-	var z = make(thinPoly, 128)
+// ws must be nil or have room for karatsuba2Workspace coefficients.
+func (p thinPoly) Karatsuba2(f, g, ws thinPoly) thinPoly {
+	ws = workspace(ws, karatsuba2Workspace)
+	t, z, w := ws[:64], ws[64:192], ws[192:]
 	f0, f1 := f[:32], f[32:]
 	g0, g1 := g[:32], g[32:]
 
-	t.Karatsuba3(f0, g0)
+	t.Karatsuba3(f0, g0, w)
 	z.Set(t)
-	t.Karatsuba3(f1, g1)
+	z[64:].Zero()
+	t.Karatsuba3(f1, g1, w)
 	z[32:].Inc(t.Mul(-1, t))
 
 	p.Set(z)
 	p[32:].Inc(z.Mul(-1, z)[:96])
-	t.Karatsuba3(z.Add(f0, f1), z[32:].Add(g0, g1))
+	t.Karatsuba3(z.Add(f0, f1), z[32:].Add(g0, g1), w)
 	p[32:].Inc(t)
 
 	return p
 }
 
 // Karatsuba1 uses Karatsuba2 to implement 128n x 128n.
-func (p thinPoly) Karatsuba1(f, g thinPoly) thinPoly {
-	var t = make(thinPoly, 128)
-	var z = make(thinPoly, 256)
+// ws must be nil or have room for karatsuba1Workspace coefficients.
+func (p thinPoly) Karatsuba1(f, g, ws thinPoly) thinPoly {
+	ws = workspace(ws, karatsuba1Workspace)
+	t, z, w := ws[:128], ws[128:384], ws[384:]
 	f0, f1 := f[:64], f[64:]
 	g0, g1 := g[:64], g[64:]
 
-	t.Karatsuba2(f0, g0)
+	t.Karatsuba2(f0, g0, w)
 	z.Set(t)
-	t.Karatsuba2(f1, g1)
+	z[128:].Zero()
+	t.Karatsuba2(f1, g1, w)
 	z[64:].Inc(t.Mul(-1, t))
 
 	p.Set(z)
 	p[64:].Inc(z.Mul(-1, z)[:192])
-	t.Karatsuba2(z.Add(f0, f1), z[64:].Add(g0, g1))
+	t.Karatsuba2(z.Add(f0, f1), z[64:].Add(g0, g1), w)
 	p[64:].Inc(t)
 
 	return p.Freeze()
 }
 
-// Map with Toom6 coefficients for selected points.
+// ExportKaratsuba1Tree returns the three sub-products computed by Karatsuba1
+// for the 128n x 128n multiplication of f and g: f0*g0, f1*g1 and
+// (f0+f1)*(g0+g1), where f0, f1 and g0, g1 are the lower and upper halves of
+// f and g. Each sub-product has 128 coefficients, the last of which is zero.
+// The product f*g can be recovered as f0g0 + (middleProd-f0g0-f1g1)*x^64 +
+// f1g1*x^128, which allows a caller to reuse f0g0 or f1g1 across products.
+func ExportKaratsuba1Tree(f, g []int32) (f0g0, f1g1, middleProd []int32) {
+	f0, f1 := f[:64], f[64:128]
+	g0, g1 := g[:64], g[64:128]
+	a := make(thinPoly, 64)
+	b := make(thinPoly, 64)
+	ws := make(thinPoly, karatsuba2Workspace)
+
+	f0g0 = make(thinPoly, 128).Karatsuba2(f0, g0, ws).Freeze()
+	f1g1 = make(thinPoly, 128).Karatsuba2(f1, g1, ws).Freeze()
+	middleProd = make(thinPoly, 128).Karatsuba2(a.Add(f0, f1), b.Add(g0, g1), ws).Freeze()
+
+	return
+}
+
+// Map with Toom6 coefficients for selected points. Toom5 uses the first five
+// coefficients of each point.
 var toomEvalCoeffs = map[int][]int32 {
 	+1: { 1, 1, 1, 1, 1, 1 },
 	-1: { 1, -1, 1, -1, 1, -1 },
@@ -191,18 +294,41 @@ var toomEvalCoeffs = map[int][]int32 {
 	+5: { 1, 5, 25, 125, 625, 3125 },
 }
 
-// toomEval evaluates the Toom6 factorization of f*g over GF(9829) at p.
-func toomEval(p int, f, g *[768]int32) []int32 {
-	a := make(thinPoly, 128)
-	b := make(thinPoly, 128)
-	t := make(thinPoly, 128)
+// Points at which Toom6 evaluates f*g, besides zero and infinity.
+var toom6Points = []int{+1, -1, +2, -2, +3, -3, +4, -4, +5}
 
-	for i,v := range toomEvalCoeffs[p] {
-		a.Inc(t.Mul(v, f[i*128:(i+1)*128]))
-		b.Inc(t.Mul(v, g[i*128:(i+1)*128]))
+// toomEvalOne evaluates f, split in blocks of 128 coefficients, at p. The
+// number of blocks selects the Toom variant. The evaluation is stored in the
+// first 128 coefficients of ws, which must be nil or have room for 256.
+func toomEvalOne(p int, f []int32, ws thinPoly) thinPoly {
+	return toomEvalBlocks(p, f, 128, ws)
+}
+
+// toomEvalBlocks is toomEvalOne for blocks of n coefficients, with f holding
+// at most six of them. ws must be nil or have room for 2n coefficients.
+func toomEvalBlocks(p int, f []int32, n int, ws thinPoly) thinPoly {
+	ws = workspace(ws, 2*n)
+	a, t := ws[:n].Zero(), ws[n:2*n]
+
+	for i,v := range toomEvalCoeffs[p][:len(f)/n] {
+		a.Inc(t.Mul(v, f[i*n:(i+1)*n]))
 	}
 
-	return make(thinPoly, 256).Karatsuba1(a.Freeze(), b.Freeze())
+	return a.Freeze()
+}
+
+// Size of the workspace used by toomEval: the evaluations of f and g, a
+// temporary, and the workspace of Karatsuba1.
+const toomEvalWorkspace = 3*128 + karatsuba1Workspace
+
+// toomEval evaluates the Toom factorization of f*g over GF(9829) at p, and
+// stores it in r. ws must be nil or have room for toomEvalWorkspace
+// coefficients.
+func (r thinPoly) toomEval(p int, f, g []int32, ws thinPoly) thinPoly {
+	ws = workspace(ws, toomEvalWorkspace)
+	fp := toomEvalOne(p, f, ws[0:256])
+	gp := toomEvalOne(p, g, ws[128:384])
+	return r.Karatsuba1(fp, gp, ws[384:])
 }
 
 // Interpolation parameters for Toom6.
@@ -219,47 +345,72 @@ var toomParam = [][]int32 {
 }
 
 // toomInterpolate performs a linear interpolation of 'points' with the
-// parameters passed in 'param'.
-func toomInterpolate(points [][]int32, param []int32) []int32 {
-	t := make(thinPoly, 256)
-	u := make(thinPoly, 256)
+// parameters passed in 'param', and stores it in r. ws must be nil or have
+// room for 256 coefficients.
+func (r thinPoly) toomInterpolate(points [][]int32, param []int32, ws thinPoly) thinPoly {
+	u := workspace(ws, 256)
+	r.Zero()
 
 	for i := range points {
-		t.Inc(u.Mul(param[i], points[i]))
+		r.Inc(u.Mul(param[i], points[i]))
 	}
 
-	return t.Freeze()
+	return r.Freeze()
 }
 
-// Toom6 decomposes a 768n x 768n multiplication into six instances of 128n x
-// 128n. It is the highest level of the multiplication algorithm.
-func (r thinPoly) Toom6(f, g *[768]int32) thinPoly {
-	var e = [][]int32 {
-		make(thinPoly, 256).Karatsuba1(f[0:128], g[0:128]),
-		toomEval(+1, f, g),
-		toomEval(-1, f, g),
-		toomEval(+2, f, g),
-		toomEval(-2, f, g),
-		toomEval(+3, f, g),
-		toomEval(-3, f, g),
-		toomEval(+4, f, g),
-		toomEval(-4, f, g),
-		toomEval(+5, f, g),
-		make(thinPoly, 256).Karatsuba1(f[640:768], g[640:768]),
+// toom6Eval computes the i-th of the eleven evaluations used by Toom6: the
+// product of the lowest blocks of f and g for i = 0, the product of their
+// highest blocks for i = 10, and the evaluation at toom6Points[i-1] otherwise.
+// The evaluation is stored in r. ws must be nil or have room for
+// toomEvalWorkspace coefficients.
+func (r thinPoly) toom6Eval(i int, f, g *[768]int32, ws thinPoly) thinPoly {
+	switch i {
+	case 0:
+		return r.Karatsuba1(f[0:128], g[0:128], ws)
+	case 10:
+		return r.Karatsuba1(f[640:768], g[640:768], ws)
 	}
-	var c = [][]int32 {
-		e[0],
-		toomInterpolate(e, toomParam[0]),
-		toomInterpolate(e, toomParam[1]),
-		toomInterpolate(e, toomParam[2]),
-		toomInterpolate(e, toomParam[3]),
-		toomInterpolate(e, toomParam[4]),
-		toomInterpolate(e, toomParam[5]),
-		toomInterpolate(e, toomParam[6]),
-		toomInterpolate(e, toomParam[7]),
-		toomInterpolate(e, toomParam[8]),
-		e[10],
+	return r.toomEval(toom6Points[i-1], f[:], g[:], ws)
+}
+
+// Size of the workspace used by Toom6: the eleven evaluations of f*g, followed
+// by the workspace of toom6EvalAll or, once the evaluations are done, that of
+// toom6Combine. The scratch size depends on whether the evaluations are run
+// in parallel; see parallel.go and noparallel.go.
+const toom6Workspace = 11*256 + toom6ScratchWorkspace
+
+// Toom6 decomposes a 768n x 768n multiplication into six instances of 128n x
+// 128n. It is the highest level of the multiplication algorithm. ws must be
+// nil or have room for toom6Workspace coefficients.
+func (r thinPoly) Toom6(f, g *[768]int32, ws thinPoly) thinPoly {
+	e, w := toom6Evaluate(f, g, ws)
+	return r.toom6Combine(e[:], w)
+}
+
+// toom6Evaluate computes the eleven evaluations used by Toom6 in the first
+// 11*256 coefficients of ws, and returns them along with the rest of ws. ws
+// must be nil or have room for toom6Workspace coefficients.
+func toom6Evaluate(f, g *[768]int32, ws thinPoly) (e [11][]int32, w thinPoly) {
+	ws = workspace(ws, toom6Workspace)
+	for i := range e {
+		e[i] = ws[i*256:(i+1)*256]
 	}
+	w = ws[11*256:]
+
+	toom6EvalAll(&e, f, g, w)
+
+	return e, w
+}
+
+// Size of the workspace used by toom6Combine: the nine interpolated products,
+// and a temporary.
+const toom6CombineWorkspace = 9*256 + 256
+
+// toom6Combine interpolates the eleven evaluations of f*g computed by Toom6
+// and assembles the resulting 1536-coefficient product in r. ws must be nil or
+// have room for toom6CombineWorkspace coefficients.
+func (r thinPoly) toom6Combine(e [][]int32, ws thinPoly) thinPoly {
+	c := toom6Interpolate(e, ws)
 
 	copy(r[:128], c[0])
 	r[128:].Add(c[0][128:], c[1][:128])
@@ -277,8 +428,119 @@ func (r thinPoly) Toom6(f, g *[768]int32) thinPoly {
 	return r
 }
 
+// toom6Interpolate recovers from the eleven evaluations of f*g computed by
+// Toom6 the eleven 256-coefficient segments c[i] of the product, which
+// overlap by 128 coefficients. ws must be nil or have room for
+// toom6CombineWorkspace coefficients.
+func toom6Interpolate(e [][]int32, ws thinPoly) (c [11][]int32) {
+	ws = workspace(ws, toom6CombineWorkspace)
+	c[0], c[10] = e[0], e[10]
+	for i := 1; i < 10; i++ {
+		c[i] = thinPoly(ws[(i-1)*256:i*256]).toomInterpolate(e, toomParam[i-1], ws[9*256:])
+	}
+	return c
+}
+
+// toom6Accumulate is like toom6Combine, but it adds the product to r instead
+// of storing it, leaving the sums unreduced.
+func (r thinPoly) toom6Accumulate(e [][]int32, ws thinPoly) thinPoly {
+	c := toom6Interpolate(e, ws)
+
+	r[:128].Inc(c[0][:128])
+	for i := 0; i < 10; i++ {
+		s := r[128*(i+1) : 128*(i+2)]
+		for j := range s {
+			s[j] += Freeze(c[i][128+j] + c[i+1][j])
+		}
+	}
+	r[1408:].Inc(c[10][128:])
+
+	return r
+}
+
+// MulWorkspaceSize is the number of coefficients of the workspace taken by
+// MulWithWorkspace. The workspace is laid out as follows:
+//
+//	ws[0:2816]  the eleven 256-coefficient products of Toom6's evaluations
+//	ws[2816:]   scratch space, used first by the evaluations and then by the
+//	            interpolation
+//
+// The scratch space is larger when the package is built with the parallel
+// tag, as each of the eleven evaluations then needs its own.
+//
+// No state is kept in the workspace between calls, so the same workspace may
+// be reused for any number of multiplications, but not by several of them at
+// once.
+const MulWorkspaceSize = toom6Workspace
+
 // Main entry point.
 func Mul(h *[1536]int32, f, g *[768]int32) {
+	ws := mulPool.Get().(*[MulWorkspaceSize]int32)
+	MulWithWorkspace(h, f, g, ws[:])
+	mulPool.Put(ws)
+}
+
+// MulWithWorkspace is like Mul, but it takes all of its intermediate values
+// from ws, which must have at least MulWorkspaceSize elements, instead of
+// allocating them. Its contents on return are unspecified. In a build with
+// the parallel tag, the goroutines that compute the evaluations of Toom6 still
+// allocate.
+func MulWithWorkspace(h *[1536]int32, f, g *[768]int32, ws []int32) {
+	if len(ws) < MulWorkspaceSize {
+		panic("karatsuba768: workspace too small")
+	}
 	z := thinPoly(h[:])
-	z.Toom6(f, g)
+	z.Toom6(f, g, ws)
+}
+
+// MulAccumulate adds f*g to h, so that a sum of products can be computed
+// without a temporary for each of them. The coefficients of f*g, each in
+// [0, 9828], are added to h without being reduced; apply Freeze to each
+// coefficient of h once all products have been added. If h starts with its
+// coefficients in [0, 9828], up to 16807 products may be added to it before
+// its coefficients leave the domain of Freeze, and none of them overflows.
+func MulAccumulate(h *[1536]int32, f, g *[768]int32) {
+	ws := mulPool.Get().(*[MulWorkspaceSize]int32)
+	e, w := toom6Evaluate(f, g, ws[:])
+	thinPoly(h[:]).toom6Accumulate(e[:], w)
+	mulPool.Put(ws)
+}
+
+// MulScratch holds every intermediate value of a multiplication, so that
+// MulScratch.Mul neither allocates nor goes through the pool used by Mul. Its
+// zero value is ready to use. A MulScratch must not be used by several
+// goroutines at once.
+type MulScratch struct {
+	ws [MulWorkspaceSize]int32
+}
+
+// Mul sets h to f*g, as Mul does, using s as its workspace.
+func (s *MulScratch) Mul(h *[1536]int32, f, g *[768]int32) {
+	MulWithWorkspace(h, f, g, s.ws[:])
+}
+
+// MulChecked is like SafeMul: it returns an error describing the first
+// coefficient of f or g not in [0, 9828], leaving h untouched, and otherwise
+// sets h to f*g. The inputs are first scanned with validCT, whose loop has no
+// branches, so that the common case costs a small fraction of Mul; only if the
+// scan fails are they walked again to locate the bad coefficient.
+func MulChecked(h *[1536]int32, f, g *[768]int32) error {
+	if !validCT(f) || !validCT(g) {
+		return SafeMul(h, f, g)
+	}
+	Mul(h, f, g)
+	return nil
+}
+
+// SafeMul is like Mul, but it first validates f and g and returns an error,
+// leaving h untouched, if any of their coefficients is not in [0, 9828].
+func SafeMul(h *[1536]int32, f, g *[768]int32) error {
+	if err := Validate(f); err != nil {
+		return fmt.Errorf("f: %v", err)
+	}
+	if err := Validate(g); err != nil {
+		return fmt.Errorf("g: %v", err)
+	}
+	Mul(h, f, g)
+	return nil
 }
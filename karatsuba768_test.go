@@ -6,13 +6,15 @@ package karatsuba768
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/rand"
 	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -27,27 +29,54 @@ func TestFreeze(t *testing.T) {
 	}
 }
 
-func loadPoly(buf *bufio.Reader, p []int32, size int) error {
-	body, chunk, err := buf.ReadLine()
-	if chunk {
-		return errors.New("input buffer too small")
+// TestFreezeIdempotent checks that Freeze leaves its own output unchanged
+// over the whole of its domain. TestFreeze implies it, but this states the
+// property directly, for any later change to the reduction steps. Under
+// -short, only every 997th input is tried.
+func TestFreezeIdempotent(t *testing.T) {
+	step := int32(1)
+	if testing.Short() {
+		step = 997
 	}
-	if err != nil {
-		return err
+	for x := int32(-165191049); x < 165191050; x += step {
+		if y := Freeze(x); Freeze(y) != y {
+			t.Fatalf("Freeze(Freeze(%d))=%d != Freeze(%d)=%d", x, Freeze(y), x, y)
+		}
 	}
-	line := string(body)
-	parts := strings.Split(line, ",")
-	if len(parts) > size {
-		return errors.New("too many parts")
+}
+
+func TestFreezeBoundary(t *testing.T) {
+	if y := Freeze(165191049); y != 4875 {
+		t.Fatalf("Freeze(165191049)=%d", y)
 	}
-	for i := range parts {
-		n, err := strconv.ParseInt(strings.TrimSpace(parts[i]), 10, 32)
-		if err != nil {
-			return err
+	if y := Freeze(-165191049); y != 4954 {
+		t.Fatalf("Freeze(-165191049)=%d", y)
+	}
+	// outside the domain, 13*x overflows; see the comment on Freeze
+	for _, x := range []int32{165191050, -165191050, 1<<31 - 1, -1 << 31} {
+		if y := Freeze(x); y >= 0 && y <= 9828 {
+			t.Fatalf("Freeze(%d)=%d is in range", x, y)
+		}
+	}
+}
+
+func TestCoefficientsAfterFreeze(t *testing.T) {
+	p := make(thinPoly, 768)
+	for i := range p {
+		switch i % 3 {
+		case 0:
+			p[i] = 165191049
+		case 1:
+			p[i] = -165191049
+		default:
+			p[i] = int32(rand.Intn(2*165191049+1) - 165191049)
+		}
+	}
+	for i, x := range p.Freeze() {
+		if x < 0 || x > 9828 {
+			t.Fatalf("x=%d out of range for i=%d", x, i)
 		}
-		p[i] = int32(n)
 	}
-	return nil
 }
 
 func cmpPoly(t *testing.T, c, d *[1536]int32) error {
@@ -71,62 +100,130 @@ func textbookMul(h *[1536]int32, f, g *[768]int32) {
 	}
 }
 
-func TestSage64(t *testing.T) {
-	// open file
-	f, err := os.Open("sage64.gz")
+// openVectors opens a file of test vectors in the format of sage64.gz,
+// decompressing it if its name ends in .gz. The file is closed along with the
+// returned reader.
+func openVectors(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		t.Fatal(err)
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
 	}
-	defer f.Close()
-
-	// create input buf
 	in, err := gzip.NewReader(f)
 	if err != nil {
-		t.Fatal(err)
+		f.Close()
+		return nil, err
+	}
+	return gzipFile{in, f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
+
+// loadVectors reads every (a, b, c = a*b) triple from the file at path, one
+// polynomial per line, gzip-compressed if the name ends in .gz.
+func loadVectors(path string) ([][768]int32, [][768]int32, [][1536]int32, error) {
+	in, err := openVectors(path)
+	if err != nil {
+		return nil, nil, nil, err
 	}
 	defer in.Close()
-	buf := bufio.NewReaderSize(in, 1 << 14)
+	buf := bufio.NewReaderSize(in, 1<<14)
 
-	for ln := 0;; ln += 3 {
-		// load a
-		t.Logf("processing line %d", ln + 1)
-		a := new([768]int32)
-		err := loadPoly(buf, a[:], 768)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			t.Fatalf("couldn't read poly: %v", err)
+	var as, bs [][768]int32
+	var cs [][1536]int32
+	for ln := 1; ; ln += 3 {
+		var a, b [768]int32
+		var c [1536]int32
+		if err := ReadPoly(buf, &a); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, nil, fmt.Errorf("line %d: %v", ln, err)
 		}
-
-		// load b
-		t.Logf("processing line %d", ln + 2)
-		b := new([768]int32)
-		err = loadPoly(buf, b[:], 768)
-		if err != nil {
-			t.Fatalf("couldn't read poly: %v", err)
+		if err := ReadPoly(buf, &b); err != nil {
+			return nil, nil, nil, fmt.Errorf("line %d: %v", ln+1, err)
 		}
-
-		// load c
-		t.Logf("processing line %d", ln + 3)
-		c := new([1536]int32)
-		err = loadPoly(buf, c[:], 1536)
-		if err != nil {
-			t.Fatalf("couldn't read poly: %v", err)
+		if err := ReadResult(buf, &c); err != nil {
+			return nil, nil, nil, fmt.Errorf("line %d: %v", ln+2, err)
 		}
+		as, bs, cs = append(as, a), append(bs, b), append(cs, c)
+	}
+	return as, bs, cs, nil
+}
 
-		// verify that c == d
+// TestSage64 checks Mul against the products computed by SageMath in
+// sage64.gz. It is skipped with -short, and also if sage64.gz is missing, so
+// a run of the full suite needs both the file and no -short flag.
+func TestSage64(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow sage vector test")
+	}
+	as, bs, cs, err := loadVectors("sage64.gz")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) == 0 {
+		t.Fatal("no vectors in sage64.gz")
+	}
+
+	for i := range as {
 		d := new([1536]int32)
-		Mul(d, a, b)
-		err = cmpPoly(t, c, d)
-		if err != nil {
-			t.Fatalf("c != d: %v", err)
+		Mul(d, &as[i], &bs[i])
+		if err := cmpPoly(t, &cs[i], d); err != nil {
+			t.Fatalf("c != d for vector %d: %v", i, err)
 		}
 	}
 }
 
+func TestLoadVectorsText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.txt")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := randPoly(new([768]int32))
+	b := randPoly(new([768]int32))
+	c := new([1536]int32)
+	Mul(c, a, b)
+	for i := 0; i < 2; i++ {
+		WritePoly(f, a)
+		WritePoly(f, b)
+		WriteResult(f, c)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	as, bs, cs, err := loadVectors(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 2 || as[1] != *a || bs[1] != *b || cs[1] != *c {
+		t.Fatal("vectors not read back")
+	}
+	if _, _, _, err := loadVectors(path + ".missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("loadVectors of a missing file returned %v", err)
+	}
+}
+
 func TestRandom64(t *testing.T) {
-	for i := 0; i < 64; i++ {
+	n := 64
+	if testing.Short() {
+		n = 4
+	}
+	for i := 0; i < n; i++ {
 		a := new([768]int32)
 		b := new([768]int32)
 		for j := 0; j < 768; j++ {
@@ -147,3 +244,718 @@ func TestRandom64(t *testing.T) {
 		}
 	}
 }
+
+// fuzzPoly decodes a polynomial from src, two little-endian bytes per
+// coefficient reduced mod 9829. Missing coefficients are set to zero and
+// excess bytes are ignored.
+func fuzzPoly(src []byte) *[768]int32 {
+	f := new([768]int32)
+	for i := 0; i < 768 && 2*i+1 < len(src); i++ {
+		f[i] = int32(uint16(src[2*i])|uint16(src[2*i+1])<<8) % 9829
+	}
+	return f
+}
+
+func FuzzMul(f *testing.F) {
+	ones := bytes.Repeat([]byte{1, 0}, 768)
+	top := make([]byte, 2*768)
+	top[2*767], top[2*767+1] = 0x64, 0x26
+	f.Add([]byte{}, []byte{})
+	f.Add(ones, ones)
+	f.Add(top, top)
+	f.Add(ones, top)
+	f.Add(bytes.Repeat([]byte{0x64, 0x26}, 768), bytes.Repeat([]byte{0x64, 0x26}, 768))
+	f.Fuzz(func(t *testing.T, a, b []byte) {
+		fa, fb := fuzzPoly(a), fuzzPoly(b)
+		c := new([1536]int32)
+		d := new([1536]int32)
+		textbookMul(c, fa, fb)
+		Mul(d, fa, fb)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d: %v", err)
+		}
+	})
+}
+
+func TestMulSelfAlias(t *testing.T) {
+	f := randPoly(new([768]int32))
+	p := make(thinPoly, 768)
+	copy(p, f[:])
+	p.Mul(-1, p)
+	for i := range p {
+		if want := (9829 - f[i]) % 9829; p[i] != want {
+			t.Fatalf("p[%d]=%d != %d", i, p[i], want)
+		}
+	}
+	p.Mul(3, p)
+	for i := range p {
+		if want := (3 * (9829 - f[i])) % 9829; p[i] != want {
+			t.Fatalf("p[%d]=%d != %d", i, p[i], want)
+		}
+	}
+}
+
+// textbookMulN returns f*g modulo 9829, for f and g of the same length.
+func textbookMulN(f, g []int32) []int32 {
+	h := make([]int32, 2*len(f))
+	for i := range f {
+		for j := range g {
+			h[i+j] = (h[i+j] + f[i]*g[j]) % 9829
+		}
+	}
+	return h
+}
+
+// testLevel checks an n x n level of the multiplication algorithm against
+// textbookMulN, reducing its output, which need not be frozen below
+// Karatsuba1.
+func testLevel(t *testing.T, n int, mul func(p, f, g thinPoly)) {
+	for i := 0; i < 16; i++ {
+		f := thinPoly(randPoly(new([768]int32))[:n])
+		g := thinPoly(randPoly(new([768]int32))[:n])
+		if i == 0 {
+			for j := range f {
+				f[j], g[j] = 9828, 9828
+			}
+		}
+		p := make(thinPoly, 2*n)
+		for j := range p {
+			p[j] = -12345
+		}
+		mul(p, f, g)
+		want := textbookMulN(f, g)
+		for j := range want {
+			if y := Freeze(p[j]); y != want[j] {
+				t.Fatalf("p[%d]=%d != %d", j, y, want[j])
+			}
+		}
+	}
+}
+
+func TestX4MulIsolated(t *testing.T) {
+	testLevel(t, 4, func(p, f, g thinPoly) { p.x4Mul(f, g) })
+}
+
+func TestKaratsuba5Isolated(t *testing.T) {
+	ws := make(thinPoly, karatsuba5Workspace)
+	testLevel(t, 8, func(p, f, g thinPoly) { p.Karatsuba5(f, g, ws) })
+}
+
+func TestKaratsuba4Isolated(t *testing.T) {
+	ws := make(thinPoly, karatsuba4Workspace)
+	testLevel(t, 16, func(p, f, g thinPoly) { p.Karatsuba4(f, g, ws) })
+}
+
+func TestKaratsuba3Isolated(t *testing.T) {
+	ws := make(thinPoly, karatsuba3Workspace)
+	testLevel(t, 32, func(p, f, g thinPoly) { p.Karatsuba3(f, g, ws) })
+}
+
+func TestKaratsuba2Isolated(t *testing.T) {
+	ws := make(thinPoly, karatsuba2Workspace)
+	testLevel(t, 64, func(p, f, g thinPoly) { p.Karatsuba2(f, g, ws) })
+}
+
+func TestKaratsuba1Isolated(t *testing.T) {
+	ws := make(thinPoly, karatsuba1Workspace)
+	testLevel(t, 128, func(p, f, g thinPoly) { p.Karatsuba1(f, g, ws) })
+}
+
+// TestToomInterpolateConsistency checks each row of toomParam against the
+// segment of the product it is meant to recover, the sum of the products of
+// the blocks of 128 coefficients f_i*g_j with i+j equal to the row, and then
+// the segments assembled as in toom6Combine against textbookMul.
+func TestToomInterpolateConsistency(t *testing.T) {
+	for n := 0; n < 10; n++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		var e [11][]int32
+		for i := range e {
+			e[i] = make(thinPoly, 256).toom6Eval(i, f, g, nil)
+		}
+
+		var c [11][]int32
+		c[0], c[10] = e[0], e[10]
+		for k := 1; k < 10; k++ {
+			c[k] = make(thinPoly, 256).toomInterpolate(e[:], toomParam[k-1], nil)
+			want := make(thinPoly, 256)
+			for i := 0; i < 6; i++ {
+				if j := k - i; j >= 0 && j < 6 {
+					want.Add(want, textbookMulN(f[128*i:128*(i+1)], g[128*j:128*(j+1)]))
+				}
+			}
+			for j := range want {
+				if c[k][j] != want[j] {
+					t.Fatalf("row %d of toomParam: c[%d]=%d != %d", k-1, j, c[k][j], want[j])
+				}
+			}
+		}
+
+		h := new([1536]int32)
+		for k := range c {
+			for j := range c[k] {
+				h[128*k+j] = Freeze(h[128*k+j] + c[k][j])
+			}
+		}
+		want := new([1536]int32)
+		textbookMul(want, f, g)
+		if err := cmpPoly(t, want, h); err != nil {
+			t.Fatalf("assembled product != textbookMul: %v", err)
+		}
+	}
+}
+
+func TestExportKaratsuba1Tree(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		f := make([]int32, 128)
+		g := make([]int32, 128)
+		for j := range f {
+			f[j] = int32(rand.Intn(9829))
+			g[j] = int32(rand.Intn(9829))
+		}
+		f0g0, f1g1, m := ExportKaratsuba1Tree(f, g)
+		c := make([]int32, 256)
+		for j := 0; j < 128; j++ {
+			c[j] += f0g0[j]
+			c[j+64] += m[j] - f0g0[j] - f1g1[j]
+			c[j+128] += f1g1[j]
+		}
+		d := make(thinPoly, 256).Karatsuba1(f, g, nil)
+		for j := range c {
+			if Freeze(c[j]) != d[j] {
+				t.Fatalf("c=%d, d=%d for j=%d", Freeze(c[j]), d[j], j)
+			}
+		}
+	}
+}
+
+func TestSafeMul(t *testing.T) {
+	a := randPoly(new([768]int32))
+	b := randPoly(new([768]int32))
+	c := new([1536]int32)
+	d := new([1536]int32)
+	Mul(c, a, b)
+	if err := SafeMul(d, a, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmpPoly(t, c, d); err != nil {
+		t.Fatalf("c != d: %v", err)
+	}
+	b[0] = 9829
+	e := new([1536]int32)
+	if err := SafeMul(e, a, b); err == nil {
+		t.Fatal("SafeMul accepted an out of range coefficient")
+	}
+	if *e != [1536]int32{} {
+		t.Fatal("SafeMul wrote to h on error")
+	}
+}
+
+func TestMulChecked(t *testing.T) {
+	a := randPoly(new([768]int32))
+	b := randPoly(new([768]int32))
+	c := new([1536]int32)
+	d := new([1536]int32)
+	Mul(c, a, b)
+	if err := MulChecked(d, a, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmpPoly(t, c, d); err != nil {
+		t.Fatalf("c != d: %v", err)
+	}
+	for _, x := range []int32{-1, 9829, -1 << 31, 1<<31 - 1} {
+		for _, i := range []int{0, 1, 2, 3, 767} {
+			b[i] = x
+			e := new([1536]int32)
+			err := MulChecked(e, a, b)
+			if err == nil {
+				t.Fatalf("MulChecked accepted b[%d]=%d", i, x)
+			}
+			if want := fmt.Sprintf("g: coefficient %d out of range: %d", i, x); err.Error() != want {
+				t.Fatalf("err=%q, want %q", err, want)
+			}
+			if *e != [1536]int32{} {
+				t.Fatal("MulChecked wrote to h on error")
+			}
+			b[i] = 0
+		}
+	}
+}
+
+func BenchmarkMulChecked(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		MulChecked(h, f, g)
+	}
+}
+
+func BenchmarkValidCT(b *testing.B) {
+	f := randPoly(new([768]int32))
+	for i := 0; i < b.N; i++ {
+		validCT(f)
+	}
+}
+
+func TestMulWithWorkspace(t *testing.T) {
+	ws := make([]int32, MulWorkspaceSize)
+	for i := range ws {
+		ws[i] = int32(rand.Intn(9829))
+	}
+	for i := 0; i < 4; i++ {
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		textbookMul(c, a, b)
+		MulWithWorkspace(d, a, b, ws)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d for i=%d: %v", i, err)
+		}
+	}
+
+	// the goroutines of a parallel build allocate
+	a := randPoly(new([768]int32))
+	d := new([1536]int32)
+	n := testing.AllocsPerRun(16, func() {
+		MulWithWorkspace(d, a, a, ws)
+	})
+	if n != 0 && !parallelBuild {
+		t.Fatalf("MulWithWorkspace made %v allocations", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MulWithWorkspace accepted a short workspace")
+		}
+	}()
+	MulWithWorkspace(d, a, a, ws[1:])
+}
+
+func TestMulAccumulate(t *testing.T) {
+	c := new([1536]int32)
+	d := new([1536]int32)
+	randPolyQ(c[:], 9829)
+	copy(d[:], c[:])
+	for i := 0; i < 4; i++ {
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		p := new([1536]int32)
+		textbookMul(p, a, b)
+		for j := range c {
+			c[j] = Freeze(c[j] + p[j])
+		}
+		MulAccumulate(d, a, b)
+	}
+	for j := range d {
+		d[j] = Freeze(d[j])
+	}
+	if err := cmpPoly(t, c, d); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMulScratch(t *testing.T) {
+	s := new(MulScratch)
+	for i := 0; i < 4; i++ {
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		textbookMul(c, a, b)
+		s.Mul(d, a, b)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d for i=%d: %v", i, err)
+		}
+	}
+
+	a := randPoly(new([768]int32))
+	d := new([1536]int32)
+	n := testing.AllocsPerRun(16, func() {
+		s.Mul(d, a, a)
+	})
+	if n != 0 && !parallelBuild {
+		t.Fatalf("MulScratch.Mul made %v allocations", n)
+	}
+}
+
+var benchSink int32
+
+func BenchmarkFreeze(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(4)
+	x := int32(0)
+	for i := 0; i < b.N; i++ {
+		x = Freeze(x + 123457)
+	}
+	benchSink = x
+}
+
+// benchmarkLevel benchmarks an n x n level of the multiplication algorithm,
+// reporting the size of both operands as throughput.
+func benchmarkLevel(b *testing.B, n int, mul func(p, f, g thinPoly)) {
+	f := thinPoly(randPoly(new([768]int32))[:n])
+	g := thinPoly(randPoly(new([768]int32))[:n])
+	p := make(thinPoly, 2*n)
+	b.ReportAllocs()
+	b.SetBytes(int64(2 * 4 * n))
+	for i := 0; i < b.N; i++ {
+		mul(p, f, g)
+	}
+}
+
+func BenchmarkX4Mul(b *testing.B) {
+	benchmarkLevel(b, 4, func(p, f, g thinPoly) { p.x4Mul(f, g) })
+}
+
+func BenchmarkKaratsuba5(b *testing.B) {
+	ws := make(thinPoly, karatsuba5Workspace)
+	benchmarkLevel(b, 8, func(p, f, g thinPoly) { p.Karatsuba5(f, g, ws) })
+}
+
+func BenchmarkKaratsuba4(b *testing.B) {
+	ws := make(thinPoly, karatsuba4Workspace)
+	benchmarkLevel(b, 16, func(p, f, g thinPoly) { p.Karatsuba4(f, g, ws) })
+}
+
+func BenchmarkKaratsuba3(b *testing.B) {
+	ws := make(thinPoly, karatsuba3Workspace)
+	benchmarkLevel(b, 32, func(p, f, g thinPoly) { p.Karatsuba3(f, g, ws) })
+}
+
+func BenchmarkKaratsuba2(b *testing.B) {
+	ws := make(thinPoly, karatsuba2Workspace)
+	benchmarkLevel(b, 64, func(p, f, g thinPoly) { p.Karatsuba2(f, g, ws) })
+}
+
+func BenchmarkKaratsuba1(b *testing.B) {
+	ws := make(thinPoly, karatsuba1Workspace)
+	benchmarkLevel(b, 128, func(p, f, g thinPoly) { p.Karatsuba1(f, g, ws) })
+}
+
+func BenchmarkToomEval(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	r := make(thinPoly, 256)
+	ws := make(thinPoly, toomEvalWorkspace)
+	b.ReportAllocs()
+	b.SetBytes(2 * 4 * 768)
+	for i := 0; i < b.N; i++ {
+		r.toomEval(+5, f[:], g[:], ws)
+	}
+}
+
+func BenchmarkToomInterpolate(b *testing.B) {
+	e := make([][]int32, 11)
+	for i := range e {
+		e[i] = randPoly(new([768]int32))[:256]
+	}
+	r := make(thinPoly, 256)
+	ws := make(thinPoly, 256)
+	b.ReportAllocs()
+	b.SetBytes(11 * 4 * 256)
+	for i := 0; i < b.N; i++ {
+		r.toomInterpolate(e, toomParam[4], ws)
+	}
+}
+
+func BenchmarkMul(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	b.ReportAllocs()
+	b.SetBytes(2 * 4 * 768)
+	for i := 0; i < b.N; i++ {
+		Mul(h, f, g)
+	}
+}
+
+func BenchmarkTextbookMul(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	b.ReportAllocs()
+	b.SetBytes(2 * 4 * 768)
+	for i := 0; i < b.N; i++ {
+		textbookMul(h, f, g)
+	}
+}
+
+// BenchmarkMulCacheWarm multiplies the same operands in every iteration, so
+// that they stay in the L1 cache.
+func BenchmarkMulCacheWarm(b *testing.B) {
+	BenchmarkMul(b)
+}
+
+// BenchmarkMulCacheCold multiplies a different set of operands in every
+// iteration, rotating through 24 MiB of them so that each set has been
+// evicted from the cache by the time it is used again.
+func BenchmarkMulCacheCold(b *testing.B) {
+	const n = 2048 // 12 KiB per set
+	f := make([][768]int32, n)
+	g := make([][768]int32, n)
+	h := make([][1536]int32, n)
+	for i := 0; i < n; i++ {
+		randPoly(&f[i])
+		randPoly(&g[i])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % n
+		Mul(&h[j], &f[j], &g[j])
+	}
+}
+
+func BenchmarkMulWithWorkspace(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	ws := make([]int32, MulWorkspaceSize)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		MulWithWorkspace(h, f, g, ws)
+	}
+}
+
+func BenchmarkMulAccumulate(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	b.ReportAllocs()
+	b.SetBytes(2 * 4 * 768)
+	for i := 0; i < b.N; i++ {
+		MulAccumulate(h, f, g)
+		if i%16807 == 16806 {
+			h = new([1536]int32)
+		}
+	}
+}
+
+func BenchmarkMulNoAlloc(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	s := new(MulScratch)
+	b.ReportAllocs()
+	b.SetBytes(2 * 4 * 768)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Mul(h, f, g)
+	}
+}
+
+func BenchmarkSafeMul(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		if err := SafeMul(h, f, g); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMulIdentity(t *testing.T) {
+	e := new([768]int32)
+	e[0] = 1
+	for i := 0; i < 8; i++ {
+		f := randPoly(new([768]int32))
+		h := new([1536]int32)
+		Mul(h, f, e)
+		for j := 0; j < 768; j++ {
+			if h[j] != f[j] {
+				t.Fatalf("h=%d, f=%d for j=%d", h[j], f[j], j)
+			}
+		}
+		for j := 768; j < 1536; j++ {
+			if h[j] != 0 {
+				t.Fatalf("h=%d for j=%d", h[j], j)
+			}
+		}
+	}
+}
+
+// dirtyResult returns a product buffer filled with -1, so that a coefficient
+// Mul fails to write shows up as out of range.
+func dirtyResult() *[1536]int32 {
+	h := new([1536]int32)
+	for i := range h {
+		h[i] = -1
+	}
+	return h
+}
+
+func TestMulZeroLeft(t *testing.T) {
+	f := randPoly(new([768]int32))
+	h := dirtyResult()
+	Mul(h, new([768]int32), f)
+	if err := cmpPoly(t, new([1536]int32), h); err != nil {
+		t.Fatalf("0*f != 0: %v", err)
+	}
+}
+
+func TestMulZeroRight(t *testing.T) {
+	f := randPoly(new([768]int32))
+	h := dirtyResult()
+	Mul(h, f, new([768]int32))
+	if err := cmpPoly(t, new([1536]int32), h); err != nil {
+		t.Fatalf("f*0 != 0: %v", err)
+	}
+}
+
+// TestMulOne is TestMulIdentity with the one on the left.
+func TestMulOne(t *testing.T) {
+	e := new([768]int32)
+	e[0] = 1
+	f := randPoly(new([768]int32))
+	want := new([1536]int32)
+	copy(want[:], f[:])
+	h := dirtyResult()
+	Mul(h, e, f)
+	if err := cmpPoly(t, want, h); err != nil {
+		t.Fatalf("1*f != f: %v", err)
+	}
+}
+
+func TestMulAllMax(t *testing.T) {
+	f := new([768]int32)
+	for i := range f {
+		f[i] = 9828
+	}
+	c := new([1536]int32)
+	h := dirtyResult()
+	textbookMul(c, f, f)
+	Mul(h, f, f)
+	for i, x := range h {
+		if x < 0 || x > 9828 {
+			t.Fatalf("h[%d]=%d out of range", i, x)
+		}
+	}
+	if err := cmpPoly(t, c, h); err != nil {
+		t.Fatalf("(-1)*(-1) wrong: %v", err)
+	}
+}
+
+func TestMulCommutative(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		Mul(c, f, g)
+		Mul(d, g, f)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("f*g != g*f: %v", err)
+		}
+	}
+}
+
+func TestMulMonomial(t *testing.T) {
+	for _, i := range []int{0, 1, 767} {
+		for _, j := range []int{0, 1, 767} {
+			for _, a := range []int32{1, 9828} {
+				for _, b := range []int32{1, 9828} {
+					f := new([768]int32)
+					g := new([768]int32)
+					f[i], g[j] = a, b
+					c := new([1536]int32)
+					c[i+j] = Freeze(a * b)
+					d := new([1536]int32)
+					Mul(d, f, g)
+					err := cmpPoly(t, c, d)
+					if err != nil {
+						t.Fatalf("%d*x^%d * %d*x^%d: %v", a, i, b, j, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestToom6DegreeConservation multiplies monomials at the edges of each
+// 128-coefficient block by x^767 with Toom6, and checks that the product has
+// a single nonzero coefficient, at the right degree. An off-by-one in the
+// assembly of the interpolated blocks would shift or smear it.
+func TestToom6DegreeConservation(t *testing.T) {
+	g := new([768]int32)
+	g[767] = 1
+	for k := 0; k < 6; k++ {
+		for _, i := range []int{128 * k, 128*k + 127} {
+			f := new([768]int32)
+			f[i] = 1
+			h := new([1536]int32)
+			thinPoly(h[:]).Toom6(f, g, nil)
+			for j, c := range h {
+				if (j == i+767) != (c != 0) || c > 1 {
+					t.Fatalf("h[%d]=%d for x^%d * x^767", j, c, i)
+				}
+			}
+		}
+	}
+}
+
+// TestMulBoundaryCoeffs checks the lowest and highest coefficients of the
+// product, where the first and last 128-coefficient blocks of the Toom
+// decomposition meet. Note that the highest-degree term of a 768n x 768n
+// product is h[1534]; h[1535] is always zero.
+func TestMulBoundaryCoeffs(t *testing.T) {
+	for _, k := range []int{0, 767} {
+		f := new([768]int32)
+		f[k] = int32(1 + rand.Intn(9828))
+		g := randPoly(new([768]int32))
+		h := new([1536]int32)
+		Mul(h, f, g)
+		if h[0] != Freeze(f[0]*g[0]) {
+			t.Fatalf("h[0]=%d for k=%d", h[0], k)
+		}
+		if h[1534] != Freeze(f[767]*g[767]) {
+			t.Fatalf("h[1534]=%d for k=%d", h[1534], k)
+		}
+		if h[1535] != 0 {
+			t.Fatalf("h[1535]=%d for k=%d", h[1535], k)
+		}
+		for j := 0; j < 1536; j++ {
+			var x int32
+			if j >= k && j < k+768 {
+				x = Freeze(f[k] * g[j-k])
+			}
+			if h[j] != x {
+				t.Fatalf("h=%d, x=%d for j=%d, k=%d", h[j], x, j, k)
+			}
+		}
+	}
+}
+
+// TestMulWithNegativeCoefficients checks that Mul accepts inputs in the
+// centered range [-4914, 4914] and gives the same result as for their
+// representatives in [0, 9828].
+func TestMulWithNegativeCoefficients(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		a := new([768]int32)
+		b := new([768]int32)
+		for j := 0; j < 768; j++ {
+			a[j] = int32(rand.Intn(9829) - 4914)
+			b[j] = int32(rand.Intn(9829) - 4914)
+		}
+		if i == 0 {
+			for j := 0; j < 768; j++ {
+				a[j], b[j] = -4914, 4914
+			}
+		}
+		a0 := new([768]int32)
+		b0 := new([768]int32)
+		for j := 0; j < 768; j++ {
+			a0[j] = Freeze(a[j])
+			b0[j] = Freeze(b[j])
+		}
+		c := new([1536]int32)
+		d := new([1536]int32)
+		Mul(c, a0, b0)
+		Mul(d, a, b)
+		err := cmpPoly(t, c, d)
+		if err != nil {
+			t.Fatalf("c != d: %v", err)
+		}
+	}
+}
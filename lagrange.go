@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"errors"
+	"fmt"
+)
+
+// LagrangeInterpolate sets out to the unique polynomial of degree less than
+// len(points) over GF(9829) that takes the value y at x for every (x, y) in
+// points. At most 768 points may be given, and no two of them may share the
+// same x modulo 9829. The polynomial is computed in Newton form using divided
+// differences and then expanded, in O(len(points)^2) operations.
+func LagrangeInterpolate(out *[768]int32, points [][2]int32) error {
+	k := len(points)
+	if k > 768 {
+		return errors.New("too many points")
+	}
+
+	var seen [9829]bool
+	x := make(thinPoly, k)
+	c := make(thinPoly, k)
+	for i := range points {
+		x[i] = Freeze(points[i][0])
+		c[i] = Freeze(points[i][1])
+		if seen[x[i]] {
+			return fmt.Errorf("duplicate x-value %d", points[i][0])
+		}
+		seen[x[i]] = true
+	}
+
+	// divided differences
+	for j := 1; j < k; j++ {
+		for i := k - 1; i >= j; i-- {
+			d := Ring768.z.inverse(x[i] - x[i-j])
+			c[i] = Freeze(Freeze(c[i]-c[i-1]) * d)
+		}
+	}
+
+	// p(x) = c[k-1]; p(x) = p(x)*(x-x[i]) + c[i]
+	p := thinPoly(out[:]).Zero()
+	if k == 0 {
+		return nil
+	}
+	p[0] = c[k-1]
+	for i := k - 2; i >= 0; i-- {
+		for j := k - 1 - i; j > 0; j-- {
+			p[j] = Freeze(p[j-1] - p[j]*x[i])
+		}
+		p[0] = Freeze(c[i] - p[0]*x[i])
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// evalPoly evaluates f at x by Horner's rule.
+func evalPoly(f []int32, x int32) int32 {
+	y := int32(0)
+	for i := len(f) - 1; i >= 0; i-- {
+		y = Freeze(y*x + f[i])
+	}
+	return y
+}
+
+func TestLagrangeInterpolate(t *testing.T) {
+	for _, k := range []int{0, 1, 2, 17, 768} {
+		f := new([768]int32)
+		for i := 0; i < k; i++ {
+			f[i] = int32(rand.Intn(9829))
+		}
+		points := make([][2]int32, k)
+		for i, x := range rand.Perm(9829)[:k] {
+			points[i][0] = int32(x)
+			points[i][1] = evalPoly(f[:], int32(x))
+		}
+		g := new([768]int32)
+		if err := LagrangeInterpolate(g, points); err != nil {
+			t.Fatal(err)
+		}
+		if *f != *g {
+			t.Fatalf("f != g for k=%d", k)
+		}
+	}
+}
+
+func TestLagrangeInterpolateDuplicate(t *testing.T) {
+	points := [][2]int32{{1, 2}, {3, 4}, {9830, 5}}
+	if err := LagrangeInterpolate(new([768]int32), points); err == nil {
+		t.Fatal("LagrangeInterpolate accepted a duplicate x-value")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// MinimalPolynomial returns the minimal polynomial of f in Z_9829[x]/(r(x)),
+// where r is given by ringMod: the monic polynomial m of lowest degree such
+// that m(f) = 0 in the ring. Its degree is at most 768, and it is returned
+// with coefficients in [0, 9828], m[i] being the coefficient of degree i.
+//
+// The powers 1, f, f^2, ... are reduced one by one against an echelon basis
+// of the previous ones, over GF(9829), until one of them is a linear
+// combination of the others; that combination gives m. This takes up to 768
+// ring multiplications and O(768^3) field operations, and its running time
+// depends on f.
+func MinimalPolynomial(f *[768]int32, ringMod *[769]int32) (*[769]int32, error) {
+	mod, err := monicModulus(ringMod)
+	if err != nil {
+		return nil, err
+	}
+
+	type row struct {
+		pivot int
+		v     thinPoly // reduced power of f, with v[pivot] = 1
+		c     thinPoly // coefficients of v in terms of 1, f, f^2, ...
+	}
+	var basis []row
+
+	fk := new([768]int32)
+	fk[0] = 1
+	g := new([768]int32)
+	thinPoly(g[:]).Set(f[:]).Freeze()
+
+	for k := 0; k <= 768; k++ {
+		v := make(thinPoly, 768).Set(fk[:])
+		c := make(thinPoly, k+1)
+		c[k] = 1
+		for _, b := range basis {
+			x := v[b.pivot]
+			if x == 0 {
+				continue
+			}
+			for i := b.pivot; i < 768; i++ {
+				v[i] = Freeze(v[i] - x*b.v[i])
+			}
+			for i := range b.c {
+				c[i] = Freeze(c[i] - x*b.c[i])
+			}
+		}
+
+		pivot := -1
+		for i := range v {
+			if v[i] != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			// c[0] + c[1]*f + ... + c[k]*f^k = 0, with c[k] = 1
+			m := new([769]int32)
+			copy(m[:], c)
+			return m, nil
+		}
+
+		inv := Ring768.z.inverse(v[pivot])
+		v.Mul(inv, v)
+		c.Mul(inv, c)
+		basis = append(basis, row{pivot, v, c})
+		mod.mul(fk, fk, g)
+	}
+
+	panic("minimal polynomial of degree above 768")
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestMinimalPolynomialOfX(t *testing.T) {
+	// the minimal polynomial of x is the (monic) ring modulus
+	r := ntruModulus()
+	f := &[768]int32{0, 1}
+	m, err := MinimalPolynomial(f, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [769]int32{9828, 9828}
+	want[768] = 1
+	if *m != want {
+		t.Fatal("minimal polynomial of x is not x^768 - x - 1")
+	}
+}
+
+func TestMinimalPolynomialConstant(t *testing.T) {
+	m, err := MinimalPolynomial(&[768]int32{1234}, ntruModulus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *m != ([769]int32{9829 - 1234, 1}) {
+		t.Fatal("minimal polynomial of 1234 is not x - 1234")
+	}
+}
+
+func TestMinimalPolynomialCyclic(t *testing.T) {
+	// in Z_9829[x]/(x^768 - 1), x^k has order 768/k for k dividing 768,
+	// and its powers are linearly independent monomials
+	r := new([769]int32)
+	r[0], r[768] = -1, 1
+	for _, k := range []int{2, 256} {
+		f := new([768]int32)
+		f[k] = 1
+		m, err := MinimalPolynomial(f, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := new([769]int32)
+		want[0], want[768/k] = 9828, 1
+		if *m != *want {
+			t.Fatalf("minimal polynomial of x^%d is not x^%d - 1", k, 768/k)
+		}
+	}
+}
+
+func TestMinimalPolynomialAnnihilates(t *testing.T) {
+	// f = x^256 + x^512 satisfies a relation of low degree in the cyclic
+	// ring; check that m(f) = 0
+	r := new([769]int32)
+	r[0], r[768] = -1, 1
+	f := new([768]int32)
+	f[256], f[512] = 1, 1
+	m, err := MinimalPolynomial(f, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := degree(m[:])
+	y := new([768]int32)
+	for i := d; i >= 0; i-- {
+		if err := MulRing(y, y, f, r); err != nil {
+			t.Fatal(err)
+		}
+		y[0] = Freeze(y[0] + m[i])
+	}
+	if *y != [768]int32{} {
+		t.Fatal("m(f) != 0")
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build montgomery
+
+package karatsuba768
+
+// montgomeryBuild is set when the package is built with the montgomery tag,
+// which makes the slice reductions use freezeMontgomery instead of Freeze or
+// its vectorised versions. Freeze itself is unchanged. freezeMontgomery beats
+// freezeGeneric by about a quarter, see BenchmarkFreezeMontgomery, but not the
+// SSE2 and AVX2 reductions, and Mul is about a third slower on amd64 with the
+// tag.
+const montgomeryBuild = true
+
+func init() {
+	if !debugBuild {
+		freezeSlice = freezeMontgomery
+	}
+}
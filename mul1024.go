@@ -0,0 +1,61 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Mul1024 sets h to the product f*g over GF(q), for polynomials of 1024
+// coefficients, where q is an odd prime below 2^15; an error is returned,
+// and h left untouched, for any other q. The last coefficient of h is set to
+// zero. The coefficients of f and g may be any int32; they are reduced first.
+//
+// For q = 9829, Mul1024 applies one level of Karatsuba above Karatsuba512,
+// down to the 128n x 128n products of Karatsuba1, eight levels in all. Other
+// moduli go through karatsuba64 with leaves of 16 coefficients, as Ring.Mul
+// does.
+func Mul1024(h *[2048]int32, f, g *[1024]int32, q int32) error {
+	z, err := newZq(q)
+	if err != nil {
+		return err
+	}
+	if q == 9829 {
+		mul1024(h, f, g, z)
+		return nil
+	}
+
+	a := make([]int64, 1024)
+	b := make([]int64, 1024)
+	c := make([]int64, 2048)
+	for i := range f {
+		a[i], b[i] = int64(z.freeze(f[i])), int64(z.freeze(g[i]))
+	}
+	karatsuba64(c, a, b, 16)
+	for i := range h {
+		h[i] = z.reduce(uint64(c[i]))
+	}
+	return nil
+}
+
+// mul1024 is Mul1024 for q = 9829.
+func mul1024(h *[2048]int32, f, g *[1024]int32, z zq) {
+	var f0, f1 = make(thinPoly, 512), make(thinPoly, 512)
+	var g0, g1 = make(thinPoly, 512), make(thinPoly, 512)
+	var t = make(thinPoly, 1024)
+	var p = make(thinPoly, 2048)
+	for i := 0; i < 512; i++ {
+		f0[i], f1[i] = z.freeze(f[i]), z.freeze(f[512+i])
+		g0[i], g1[i] = z.freeze(g[i]), z.freeze(g[512+i])
+	}
+
+	ws := make(thinPoly, karatsuba512Workspace)
+	lo := make(thinPoly, 1024).Karatsuba512(f0, g0, ws)
+	hi := make(thinPoly, 1024).Karatsuba512(f1, g1, ws)
+	mid := make(thinPoly, 1024).Karatsuba512(f0.Add(f0, f1), g0.Add(g0, g1), ws)
+
+	p.Inc(lo)
+	p[512:].Inc(mid)
+	p[512:].Inc(t.Mul(-1, lo))
+	p[512:].Inc(t.Mul(-1, hi))
+	p[1024:].Inc(hi)
+	copy(h[:], p.Freeze())
+}
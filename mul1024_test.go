@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMul1024(t *testing.T) {
+	n := 64
+	if testing.Short() {
+		n = 8
+	}
+	for _, q := range []int32{9829, 7681, 12289} {
+		for i := 0; i < n; i++ {
+			a, b := new([1024]int32), new([1024]int32)
+			for j := range a {
+				a[j] = rand.Int31n(q)
+				b[j] = rand.Int31n(q)
+			}
+			if i == 0 {
+				for j := range a {
+					a[j], b[j] = q-1, q-1
+				}
+			}
+			c := new([2048]int32)
+			d := new([2048]int32)
+			textbookMulQ(c[:], a[:], b[:], q)
+			if err := Mul1024(d, a, b, q); err != nil {
+				t.Fatal(err)
+			}
+			if *c != *d {
+				t.Fatalf("c != d for q=%d, i=%d", q, i)
+			}
+		}
+	}
+	if err := Mul1024(new([2048]int32), new([1024]int32), new([1024]int32), 9828); err == nil {
+		t.Fatal("Mul1024 accepted q=9828")
+	}
+}
+
+func BenchmarkMul1024(b *testing.B) {
+	f, g := new([1024]int32), new([1024]int32)
+	for j := range f {
+		f[j] = rand.Int31n(9829)
+		g[j] = rand.Int31n(9829)
+	}
+	h := new([2048]int32)
+	for i := 0; i < b.N; i++ {
+		Mul1024(h, f, g, 9829)
+	}
+}
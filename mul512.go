@@ -0,0 +1,73 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Sizes of the workspaces used by Karatsuba256 and Karatsuba512: the two
+// sums of halves and the three half-size products, followed by the workspace
+// of the level below.
+const (
+	karatsuba256Workspace = 2*128 + 3*256 + karatsuba1Workspace
+	karatsuba512Workspace = 2*256 + 3*512 + karatsuba256Workspace
+)
+
+// Karatsuba256 uses Karatsuba1 to implement 256n x 256n. The product has 511
+// coefficients; p must have room for 512, the last of which is set to zero.
+// ws must be nil or have room for karatsuba256Workspace coefficients.
+func (p thinPoly) Karatsuba256(f, g, ws thinPoly) thinPoly {
+	ws = workspace(ws, karatsuba256Workspace)
+	a, b := ws[:128], ws[128:256]
+	lo, hi, mid := ws[256:512], ws[512:768], ws[768:1024]
+	w := ws[1024:]
+	f0, f1 := f[:128], f[128:256]
+	g0, g1 := g[:128], g[128:256]
+
+	lo.Karatsuba1(f0, g0, w)
+	hi.Karatsuba1(f1, g1, w)
+	mid.Karatsuba1(a.Add(f0, f1), b.Add(g0, g1), w)
+
+	p[:512].Zero()
+	p.Inc(lo)
+	p[256:].Inc(hi)
+	p[128:].Inc(mid)
+	p[128:].Inc(lo.Mul(-1, lo))
+	p[128:].Inc(hi.Mul(-1, hi))
+
+	return p[:512].Freeze()
+}
+
+// Karatsuba512 uses Karatsuba256 to implement 512n x 512n. The product has
+// 1023 coefficients; p must have room for 1024, the last of which is set to
+// zero. ws must be nil or have room for karatsuba512Workspace coefficients.
+func (p thinPoly) Karatsuba512(f, g, ws thinPoly) thinPoly {
+	ws = workspace(ws, karatsuba512Workspace)
+	a, b := ws[:256], ws[256:512]
+	lo, hi, mid := ws[512:1024], ws[1024:1536], ws[1536:2048]
+	w := ws[2048:]
+	f0, f1 := f[:256], f[256:512]
+	g0, g1 := g[:256], g[256:512]
+
+	lo.Karatsuba256(f0, g0, w)
+	hi.Karatsuba256(f1, g1, w)
+	mid.Karatsuba256(a.Add(f0, f1), b.Add(g0, g1), w)
+
+	p[:1024].Zero()
+	p.Inc(lo)
+	p[512:].Inc(hi)
+	p[256:].Inc(mid)
+	p[256:].Inc(lo.Mul(-1, lo))
+	p[256:].Inc(hi.Mul(-1, hi))
+
+	return p[:1024].Freeze()
+}
+
+// Mul512 sets h to the product f*g over GF(9829), for polynomials of 512
+// coefficients, by two levels of Karatsuba above Karatsuba1, which take nine
+// 128n x 128n products. The last coefficient of h is set to zero. Its
+// workspace is borrowed from the pool used by Mul.
+func Mul512(h *[1024]int32, f, g *[512]int32) {
+	ws := mulPool.Get().(*[MulWorkspaceSize]int32)
+	thinPoly(h[:]).Karatsuba512(f[:], g[:], ws[:karatsuba512Workspace])
+	mulPool.Put(ws)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestMul512(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		a, b := new([512]int32), new([512]int32)
+		randPolyQ(a[:], 9829)
+		randPolyQ(b[:], 9829)
+		if i == 0 {
+			for j := range a {
+				a[j], b[j] = 9828, 9828
+			}
+		}
+		c := new([1024]int32)
+		d := new([1024]int32)
+		textbookMulQ(c[:], a[:], b[:], 9829)
+		Mul512(d, a, b)
+		if *c != *d {
+			t.Fatalf("c != d for i=%d", i)
+		}
+	}
+}
+
+func BenchmarkMul512(b *testing.B) {
+	f, g := new([512]int32), new([512]int32)
+	randPolyQ(f[:], 9829)
+	randPolyQ(g[:], 9829)
+	h := new([1024]int32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Mul512(h, f, g)
+	}
+}
+
+func BenchmarkTextbookMul512(b *testing.B) {
+	f, g := new([512]int32), new([512]int32)
+	randPolyQ(f[:], 9829)
+	randPolyQ(g[:], 9829)
+	h := new([1024]int32)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		textbookMulQ(h[:], f[:], g[:], 9829)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Interpolation parameters for Toom5, over the points 0, +1, -1, +2, -2, +3,
+// -3, +4 and infinity.
+var toom5Param = [][]int32{
+	{2457, 1, 3931, 6880, 983, 7208, 5991, 2036, 144},
+	{3548, 2458, 2458, 1720, 1720, 3877, 3877, 0, 9793},
+	{8942, 6006, 7208, 5693, 7358, 6771, 7754, 9242, 9633},
+	{6007, 5119, 5119, 9010, 9010, 7440, 7440, 0, 49},
+	{5870, 1297, 8969, 1720, 2976, 4191, 1488, 2976, 56},
+	{273, 7167, 7167, 8928, 8928, 8341, 8341, 0, 9815},
+	{2389, 7440, 4464, 5365, 8341, 1488, 4425, 5404, 9825},
+}
+
+// Toom5 decomposes a 640n x 640n multiplication into five instances of 128n x
+// 128n.
+func (r thinPoly) Toom5(f, g *[640]int32) thinPoly {
+	ws := make(thinPoly, toomEvalWorkspace)
+	var e = [][]int32{
+		make(thinPoly, 256).Karatsuba1(f[0:128], g[0:128], ws),
+		make(thinPoly, 256).toomEval(+1, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(-1, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(+2, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(-2, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(+3, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(-3, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(+4, f[:], g[:], ws),
+		make(thinPoly, 256).Karatsuba1(f[512:640], g[512:640], ws),
+	}
+	var c = [][]int32{
+		e[0],
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[0], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[1], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[2], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[3], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[4], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[5], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom5Param[6], ws),
+		e[8],
+	}
+
+	copy(r[:128], c[0])
+	r[128:].Add(c[0][128:], c[1][:128])
+	r[256:].Add(c[1][128:], c[2][:128])
+	r[384:].Add(c[2][128:], c[3][:128])
+	r[512:].Add(c[3][128:], c[4][:128])
+	r[640:].Add(c[4][128:], c[5][:128])
+	r[768:].Add(c[5][128:], c[6][:128])
+	r[896:].Add(c[6][128:], c[7][:128])
+	r[1024:].Add(c[7][128:], c[8][:128])
+	copy(r[1152:], c[8][128:])
+
+	return r
+}
+
+// Mul640 sets h to the product f*g over GF(9829), for polynomials of 640
+// coefficients.
+func Mul640(h *[1279]int32, f, g *[640]int32) {
+	z := thinPoly(h[:])
+	z.Toom5(f, g)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func textbookMul640(h *[1279]int32, f, g *[640]int32) {
+	for i := 0; i < 640; i++ {
+		for j := 0; j < 640; j++ {
+			h[i+j] = (h[i+j] + f[i]*g[j]) % 9829
+		}
+	}
+}
+
+func TestMul640(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		a := new([640]int32)
+		b := new([640]int32)
+		for j := range a {
+			a[j] = int32(rand.Intn(9829))
+			b[j] = int32(rand.Intn(9829))
+		}
+		c := new([1279]int32)
+		d := new([1279]int32)
+		textbookMul640(c, a, b)
+		Mul640(d, a, b)
+		if *c != *d {
+			t.Fatalf("c != d for i=%d", i)
+		}
+	}
+}
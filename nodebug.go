@@ -0,0 +1,19 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build !debug
+
+package karatsuba768
+
+const debugBuild = false
+
+// SetFreezePanic does nothing unless the package is built with the debug
+// tag. See debug.go.
+func SetFreezePanic(enable bool) {}
+
+func checkFreeze(x int32) {}
+
+func checkInc(p, x []int32) {}
+
+func checkAlias(p, x []int32) {}
@@ -0,0 +1,9 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build !montgomery
+
+package karatsuba768
+
+const montgomeryBuild = false
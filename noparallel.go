@@ -0,0 +1,22 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build !parallel
+
+package karatsuba768
+
+const parallelBuild = false
+
+// Size of the scratch space used by Toom6. The evaluations run one after the
+// other and share the workspace of toomEval, which is smaller than that of
+// toom6Combine.
+const toom6ScratchWorkspace = toom6CombineWorkspace
+
+// toom6EvalAll computes the eleven evaluations used by Toom6, one after the
+// other. See parallel.go for the parallel version.
+func toom6EvalAll(e *[11][]int32, f, g *[768]int32, ws thinPoly) {
+	for i := range e {
+		thinPoly(e[i]).toom6Eval(i, f, g, ws)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// toomK describes a Toom-k multiplication modulo a prime q, in which each
+// operand is split in k blocks of b coefficients, evaluated at zero,
+// infinity and the points below, and multiplied blockwise with karatsuba64.
+type toomK struct {
+	z      zq
+	k, b   int
+	points []int32
+	param  [][]int32 // as toomParam, for the 2k-3 middle blocks of the product
+}
+
+func mustToomK(q int32, k, b int, points []int32, param [][]int32) *toomK {
+	z, err := newZq(q)
+	if err != nil {
+		panic(err)
+	}
+	return &toomK{z: z, k: k, b: b, points: points, param: param}
+}
+
+var toom509 = mustToomK(4591, 4, 128, []int32{+1, -1, +2, -2, +3}, [][]int32{
+	{1530, 1, 2295, 3443, 2066, 4438, 4579},
+	{3442, 1531, 1531, 1339, 1339, 0, 4},
+	{383, 382, 1339, 4400, 1339, 1339, 15},
+	{1148, 765, 765, 3252, 3252, 0, 4586},
+	{2678, 1913, 3252, 1339, 1186, 3405, 4588},
+})
+
+var toom761 = mustToomK(4591, 6, 128, []int32{+1, -1, +2, -2, +3, -3, +4, -4, +5}, [][]int32{
+	{918, 1, 3060, 1530, 656, 3498, 1421, 4509, 4090, 3272, 1711},
+	{3665, 919, 919, 459, 459, 3221, 3221, 455, 455, 0, 576},
+	{3858, 4584, 2759, 4364, 622, 111, 3758, 1442, 1269, 188, 4100},
+	{2559, 3851, 3851, 896, 896, 2372, 2372, 3079, 3079, 0, 3771},
+	{3161, 1819, 3625, 4186, 2541, 1253, 2537, 970, 3799, 3655, 3226},
+	{526, 389, 389, 4100, 4100, 3998, 3998, 432, 432, 0, 273},
+	{813, 915, 2321, 3405, 3272, 1820, 841, 2886, 2644, 4038, 150},
+	{2431, 1728, 1728, 3727, 3727, 4182, 4182, 625, 625, 0, 4561},
+	{432, 4159, 4303, 288, 2091, 2500, 625, 3966, 1971, 2620, 4586},
+})
+
+var toom953 = mustToomK(6343, 8, 120, []int32{+1, -1, +2, -2, +3, -3, +4, -4, +5, -5, +6, -6, +7}, [][]int32{
+	{906, 1, 1585, 3964, 3436, 4405, 4581, 1850, 1802, 173, 5257, 5438, 3973, 687, 5739},
+	{4902, 907, 907, 3228, 3228, 537, 537, 5182, 5182, 3272, 3272, 3452, 3452, 0, 4617},
+	{1112, 5809, 4931, 4533, 993, 1917, 1663, 2677, 5824, 7, 2625, 2251, 4175, 5884, 1373},
+	{4106, 578, 578, 5021, 5021, 2203, 2203, 4323, 4323, 3356, 3356, 1495, 1495, 0, 710},
+	{3038, 4684, 5857, 5728, 2045, 1657, 3971, 3024, 3579, 4399, 1879, 260, 6093, 4530, 89},
+	{5292, 1379, 1379, 139, 139, 3441, 3441, 1158, 1158, 2522, 2522, 1401, 1401, 0, 4518},
+	{5587, 328, 995, 4428, 1033, 1882, 4902, 69, 5537, 3897, 4216, 3110, 1009, 1065, 504},
+	{4456, 5915, 5915, 5669, 5669, 4751, 4751, 4806, 4806, 4463, 4463, 3883, 3883, 0, 6271},
+	{2988, 4028, 3429, 4335, 5494, 793, 3807, 2073, 1444, 3009, 869, 1058, 785, 3946, 4351},
+	{3517, 673, 673, 1047, 1047, 4908, 4908, 5151, 5151, 6280, 6280, 2383, 2383, 0, 3003},
+	{2216, 4190, 4618, 3169, 3645, 4415, 4864, 4577, 1275, 769, 4111, 641, 72, 5839, 637},
+	{3098, 63, 63, 3925, 3925, 3189, 3189, 4752, 4752, 5479, 5479, 72, 72, 0, 6252},
+	{3182, 3161, 785, 5558, 2383, 3960, 1584, 4759, 5911, 432, 72, 6271, 2922, 3421, 6336},
+})
+
+// blockMul returns the 2b-coefficient product of the blocks f and g, reduced
+// modulo q.
+func (t *toomK) blockMul(f, g []int32) []int32 {
+	leaf := t.b
+	for leaf > 32 && leaf%2 == 0 {
+		leaf /= 2
+	}
+	a := make([]int64, t.b)
+	c := make([]int64, t.b)
+	for i := range a {
+		a[i], c[i] = int64(f[i]), int64(g[i])
+	}
+	p := make([]int64, 2*t.b)
+	karatsuba64(p, a, c, leaf)
+	r := make([]int32, 2*t.b)
+	for i := range r {
+		r[i] = t.z.reduce(uint64(p[i]))
+	}
+	return r
+}
+
+// eval returns the evaluation of f, split in k blocks, at x.
+func (t *toomK) eval(f []int32, x int32) []int32 {
+	acc := make([]uint64, t.b)
+	w := int32(1)
+	for i := 0; i < t.k; i++ {
+		for j := range acc {
+			acc[j] += uint64(w) * uint64(f[i*t.b+j])
+		}
+		w = t.z.freeze(w * x)
+	}
+	r := make([]int32, t.b)
+	for j := range r {
+		r[j] = t.z.reduce(acc[j])
+	}
+	return r
+}
+
+// mul sets h to f*g modulo q. f and g have at most k*b coefficients, and h
+// has room for 2*len(f) coefficients.
+func (t *toomK) mul(h, f, g []int32) {
+	k, b := t.k, t.b
+	fp := make([]int32, k*b)
+	gp := make([]int32, k*b)
+	copy(fp, f)
+	copy(gp, g)
+
+	e := make([][]int32, 0, 2*k-1)
+	e = append(e, t.blockMul(fp[:b], gp[:b]))
+	for _, x := range t.points {
+		e = append(e, t.blockMul(t.eval(fp, x), t.eval(gp, x)))
+	}
+	e = append(e, t.blockMul(fp[(k-1)*b:], gp[(k-1)*b:]))
+
+	// c[0] = e[0], c[2k-2] = e[2k-2], and the middle blocks are interpolated
+	acc := make([]uint64, 2*k*b)
+	for j, v := range e[0] {
+		acc[j] += uint64(v)
+	}
+	for i, row := range t.param {
+		for j := 0; j < 2*b; j++ {
+			var s uint64
+			for l, c := range row {
+				s += uint64(c) * uint64(e[l][j])
+			}
+			acc[(i+1)*b+j] += uint64(t.z.reduce(s))
+		}
+	}
+	for j, v := range e[2*k-2] {
+		acc[(2*k-2)*b+j] += uint64(v)
+	}
+
+	for i := range h {
+		h[i] = t.z.reduce(acc[i])
+	}
+}
+
+// Mul509 sets h to f*g over GF(4591), using Toom4 with blocks of 128
+// coefficients. The product has 1017 coefficients; h[1017] is always zero.
+func Mul509(h *[1018]int32, f, g *[509]int32) {
+	toom509.mul(h[:], f[:], g[:])
+}
+
+// Mul761 sets h to f*g over GF(4591), as needed by sntrup761, using Toom6
+// with blocks of 128 coefficients. The product has 1521 coefficients;
+// h[1521] is always zero.
+func Mul761(h *[1522]int32, f, g *[761]int32) {
+	toom761.mul(h[:], f[:], g[:])
+}
+
+// Mul953 sets h to f*g over GF(6343), as needed by sntrup953, using Toom8
+// with blocks of 120 coefficients. The product has 1905 coefficients;
+// h[1905] is always zero.
+func Mul953(h *[1906]int32, f, g *[953]int32) {
+	toom953.mul(h[:], f[:], g[:])
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// textbookMulQ multiplies f and g over GF(q).
+func textbookMulQ(h, f, g []int32, q int32) {
+	for i := range h {
+		h[i] = 0
+	}
+	for i := range f {
+		for j := range g {
+			h[i+j] = int32((int64(h[i+j]) + int64(f[i])*int64(g[j])) % int64(q))
+		}
+	}
+}
+
+func randPolyQ(f []int32, q int32) []int32 {
+	for i := range f {
+		f[i] = rand.Int31n(q)
+	}
+	return f
+}
+
+func TestMulNTRUPrime(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		{
+			f, g := new([509]int32), new([509]int32)
+			randPolyQ(f[:], 4591)
+			randPolyQ(g[:], 4591)
+			c, d := new([1018]int32), new([1018]int32)
+			textbookMulQ(c[:], f[:], g[:], 4591)
+			Mul509(d, f, g)
+			if *c != *d {
+				t.Fatalf("Mul509: c != d for i=%d", i)
+			}
+		}
+		{
+			f, g := new([761]int32), new([761]int32)
+			randPolyQ(f[:], 4591)
+			randPolyQ(g[:], 4591)
+			c, d := new([1522]int32), new([1522]int32)
+			textbookMulQ(c[:], f[:], g[:], 4591)
+			Mul761(d, f, g)
+			if *c != *d {
+				t.Fatalf("Mul761: c != d for i=%d", i)
+			}
+		}
+		{
+			f, g := new([953]int32), new([953]int32)
+			randPolyQ(f[:], 6343)
+			randPolyQ(g[:], 6343)
+			if i == 0 {
+				for j := range f {
+					f[j], g[j] = 6342, 6342
+				}
+			}
+			c, d := new([1906]int32), new([1906]int32)
+			textbookMulQ(c[:], f[:], g[:], 6343)
+			Mul953(d, f, g)
+			if *c != *d {
+				t.Fatalf("Mul953: c != d for i=%d", i)
+			}
+		}
+	}
+}
+
+func BenchmarkMul761(b *testing.B) {
+	f := new([761]int32)
+	g := new([761]int32)
+	randPolyQ(f[:], 4591)
+	randPolyQ(g[:], 4591)
+	h := new([1522]int32)
+	for i := 0; i < b.N; i++ {
+		Mul761(h, f, g)
+	}
+}
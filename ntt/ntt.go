@@ -0,0 +1,156 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+// Package ntt multiplies polynomials of 1024 coefficients with the number
+// theoretic transform, for primes q such that 2048 divides q-1, such as
+// 12289. It complements karatsuba768, whose algorithm is tied to 768
+// coefficients and a prime without such roots of unity, and serves as a
+// baseline to compare it with.
+package ntt
+
+import (
+	"fmt"
+	"sync"
+)
+
+// n is the length of the transform, which holds the full product of two
+// polynomials of 1024 coefficients.
+const n = 2048
+
+// tables holds the powers of a primitive n-th root of unity w modulo q and
+// of its inverse, and the inverse of n.
+type tables struct {
+	q    uint64
+	w    [n / 2]uint64
+	winv [n / 2]uint64
+	ninv uint64
+}
+
+var cache sync.Map // q -> *tables
+
+func pow(x, e, q uint64) uint64 {
+	r := uint64(1)
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = r * x % q
+		}
+		x = x * x % q
+	}
+	return r
+}
+
+// primeFactors returns the distinct prime factors of m, by trial division.
+func primeFactors(m uint64) []uint64 {
+	var p []uint64
+	for d := uint64(2); d*d <= m; d++ {
+		if m%d == 0 {
+			p = append(p, d)
+			for m%d == 0 {
+				m /= d
+			}
+		}
+	}
+	if m > 1 {
+		p = append(p, m)
+	}
+	return p
+}
+
+// newTables returns the tables for q, or an error if q is not a prime such
+// that n divides q-1.
+func newTables(q int32) (*tables, error) {
+	if q < 2 || (q-1)%n != 0 {
+		return nil, fmt.Errorf("ntt: %d is not 1 modulo %d", q, n)
+	}
+	if p := primeFactors(uint64(q)); len(p) != 1 || p[0] != uint64(q) {
+		return nil, fmt.Errorf("ntt: %d is not prime", q)
+	}
+	f := primeFactors(uint64(q - 1))
+
+	// find a generator of the multiplicative group
+	t := &tables{q: uint64(q)}
+	g := uint64(2)
+	for ; ; g++ {
+		ok := true
+		for _, p := range f {
+			if pow(g, (t.q-1)/p, t.q) == 1 {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			break
+		}
+	}
+	w := pow(g, (t.q-1)/n, t.q)
+	winv := pow(w, t.q-2, t.q)
+	t.w[0], t.winv[0] = 1, 1
+	for i := 1; i < n/2; i++ {
+		t.w[i] = t.w[i-1] * w % t.q
+		t.winv[i] = t.winv[i-1] * winv % t.q
+	}
+	t.ninv = pow(n, t.q-2, t.q)
+	return t, nil
+}
+
+func tablesFor(q int32) *tables {
+	if t, ok := cache.Load(q); ok {
+		return t.(*tables)
+	}
+	t, err := newTables(q)
+	if err != nil {
+		panic(err)
+	}
+	cache.Store(q, t)
+	return t
+}
+
+// transform replaces a by its transform with the roots in w, by the
+// iterative Cooley-Tukey algorithm. The output is in natural order.
+func (t *tables) transform(a *[n]uint64, w *[n / 2]uint64) {
+	for i, j := 1, 0; i < n; i++ {
+		b := n >> 1
+		for ; j&b != 0; b >>= 1 {
+			j ^= b
+		}
+		j ^= b
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	q := t.q
+	for m := 2; m <= n; m <<= 1 {
+		h, s := m/2, n/m
+		for k := 0; k < n; k += m {
+			for j := 0; j < h; j++ {
+				u := a[k+j]
+				v := a[k+j+h] * w[j*s] % q
+				a[k+j] = (u + v) % q
+				a[k+j+h] = (u + q - v) % q
+			}
+		}
+	}
+}
+
+// NTTMul sets h to f*g over Z_q, with coefficients in [0, q-1]. The
+// coefficients of f and g may be any int32; they are reduced first. q must be
+// a prime such that 2048 divides q-1, or NTTMul panics. The tables for q are
+// computed on first use and kept for later calls.
+func NTTMul(h *[2048]int32, f, g *[1024]int32, q int32) {
+	t := tablesFor(q)
+	var a, b [n]uint64
+	for i := range f {
+		a[i] = uint64((int64(f[i])%int64(q) + int64(q)) % int64(q))
+		b[i] = uint64((int64(g[i])%int64(q) + int64(q)) % int64(q))
+	}
+	t.transform(&a, &t.w)
+	t.transform(&b, &t.w)
+	for i := range a {
+		a[i] = a[i] * b[i] % t.q
+	}
+	t.transform(&a, &t.winv)
+	for i := range h {
+		h[i] = int32(a[i] * t.ninv % t.q)
+	}
+}
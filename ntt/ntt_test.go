@@ -0,0 +1,69 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package ntt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func textbookMul(h *[2048]int32, f, g *[1024]int32, q int32) {
+	var t [2048]int64
+	for i := range f {
+		for j := range g {
+			t[i+j] = (t[i+j] + int64(f[i])*int64(g[j])) % int64(q)
+		}
+	}
+	for i := range h {
+		h[i] = int32((t[i] + int64(q)) % int64(q))
+	}
+}
+
+func TestNTTMul(t *testing.T) {
+	for _, q := range []int32{12289, 18433, 40961} {
+		for i := 0; i < 4; i++ {
+			f, g := new([1024]int32), new([1024]int32)
+			for j := range f {
+				f[j] = rand.Int31n(q)
+				g[j] = rand.Int31n(q)
+			}
+			if i == 0 {
+				f[3] = -1
+				g[1023] = 1<<31 - 1
+			}
+			c, d := new([2048]int32), new([2048]int32)
+			textbookMul(c, f, g, q)
+			NTTMul(d, f, g, q)
+			if *c != *d {
+				t.Fatalf("NTTMul differs from textbookMul for q=%d", q)
+			}
+		}
+	}
+}
+
+func TestNTTMulBadModulus(t *testing.T) {
+	for _, q := range []int32{7681, 2049 * 3, 0, -12289} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("NTTMul accepted q=%d", q)
+				}
+			}()
+			NTTMul(new([2048]int32), new([1024]int32), new([1024]int32), q)
+		}()
+	}
+}
+
+func BenchmarkNTTMul(b *testing.B) {
+	f, g := new([1024]int32), new([1024]int32)
+	for j := range f {
+		f[j] = rand.Int31n(12289)
+		g[j] = rand.Int31n(12289)
+	}
+	h := new([2048]int32)
+	for i := 0; i < b.N; i++ {
+		NTTMul(h, f, g, 12289)
+	}
+}
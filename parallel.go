@@ -0,0 +1,43 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build parallel
+
+package karatsuba768
+
+import (
+	"runtime"
+	"sync"
+)
+
+const parallelBuild = true
+
+// Size of the scratch space used by Toom6. Each evaluation has its own
+// workspace of toomEval, and together they are larger than the workspace of
+// toom6Combine.
+const toom6ScratchWorkspace = 11 * toomEvalWorkspace
+
+// toom6EvalAll computes the eleven evaluations used by Toom6, each in its own
+// goroutine, unless GOMAXPROCS is 1. The i-th evaluation uses the i-th
+// toomEvalWorkspace coefficients of ws. See noparallel.go for the sequential
+// version.
+func toom6EvalAll(e *[11][]int32, f, g *[768]int32, ws thinPoly) {
+	if runtime.GOMAXPROCS(0) == 1 {
+		for i := range e {
+			thinPoly(e[i]).toom6Eval(i, f, g, ws)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(e))
+	for i := range e {
+		go func(i int) {
+			defer wg.Done()
+			w := ws[i*toomEvalWorkspace : (i+1)*toomEvalWorkspace]
+			thinPoly(e[i]).toom6Eval(i, f, g, w)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build parallel
+
+package karatsuba768
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestMulParallel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	for _, n := range []int{1, 2, 8} {
+		runtime.GOMAXPROCS(n)
+		for i := 0; i < 4; i++ {
+			a := randPoly(new([768]int32))
+			b := randPoly(new([768]int32))
+			c := new([1536]int32)
+			d := new([1536]int32)
+			textbookMul(c, a, b)
+			Mul(d, a, b)
+			if err := cmpPoly(t, c, d); err != nil {
+				t.Fatalf("c != d for GOMAXPROCS=%d: %v", n, err)
+			}
+		}
+	}
+}
+
+// BenchmarkMulParallel measures Mul with the evaluations of Toom6 spread over
+// all available CPUs. Compare with BenchmarkMul in a build without the
+// parallel tag, or with -cpu 1.
+func BenchmarkMulParallel(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	ws := make([]int32, MulWorkspaceSize)
+	for i := 0; i < b.N; i++ {
+		MulWithWorkspace(h, f, g, ws)
+	}
+}
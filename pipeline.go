@@ -0,0 +1,37 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Toom6Pipeline is a variant of Toom6 in which the eleven evaluations are
+// queued on a channel and computed by one worker goroutine per available
+// CPU, while the calling goroutine feeds the queue. The result is the same as
+// that of Toom6.
+func (r thinPoly) Toom6Pipeline(f, g *[768]int32) thinPoly {
+	var wg sync.WaitGroup
+	e := make([][]int32, 11)
+	jobs := make(chan int)
+
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				e[i] = make(thinPoly, 256).toom6Eval(i, f, g, nil)
+			}
+		}()
+	}
+	for i := range e {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return r.toom6Combine(e, nil)
+}
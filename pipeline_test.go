@@ -0,0 +1,30 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestToom6Pipeline(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		Mul(c, a, b)
+		thinPoly(d[:]).Toom6Pipeline(a, b)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d: %v", err)
+		}
+	}
+}
+
+func BenchmarkToom6Pipeline(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		thinPoly(h[:]).Toom6Pipeline(f, g)
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"math/big"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Poly768 is a polynomial of degree less than 768 over GF(9829). Its
+// coefficients are expected to be in [0, 9828], which Freeze ensures.
+type Poly768 [768]int32
+
+// Result1536 is the product of two Poly768s, of degree less than 1535.
+type Result1536 [1536]int32
+
+// NewPoly768 returns a new zero polynomial.
+func NewPoly768() *Poly768 {
+	return new(Poly768)
+}
+
+// SetCoeff sets the coefficient of x^i in p to v reduced modulo 9829. v must
+// be in the range accepted by Freeze.
+func (p *Poly768) SetCoeff(i int, v int32) {
+	p[i] = Freeze(v)
+}
+
+// Coeff returns the coefficient of x^i in p.
+func (p *Poly768) Coeff(i int) int32 {
+	return p[i]
+}
+
+// Freeze reduces every coefficient of p modulo 9829 and returns p.
+func (p *Poly768) Freeze() *Poly768 {
+	thinPoly(p[:]).Freeze()
+	return p
+}
+
+// String implements fmt.Stringer. It returns the terms of p of degree up to
+// 10, such as Poly768[9829]{1 + 2·x + 3·x^2 + ...}, with an ellipsis if p has
+// terms of higher degree.
+func (p *Poly768) String() string {
+	var b strings.Builder
+	b.WriteString("Poly768[9829]{")
+	n := 0
+	for i := 0; i <= 10; i++ {
+		if p[i] == 0 {
+			continue
+		}
+		if n > 0 {
+			b.WriteString(" + ")
+		}
+		n++
+		b.WriteString(strconv.Itoa(int(p[i])))
+		switch {
+		case i == 1:
+			b.WriteString("·x")
+		case i > 1:
+			fmt.Fprintf(&b, "·x^%d", i)
+		}
+	}
+	if degree(p[11:]) >= 0 {
+		if n > 0 {
+			b.WriteString(" + ")
+		}
+		n++
+		b.WriteString("...")
+	}
+	if n == 0 {
+		b.WriteString("0")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// GoString implements fmt.GoStringer. It returns a Go expression for p, such
+// as &karatsuba768.Poly768{1, 2, 3}, omitting the zero coefficients past the
+// last nonzero one.
+func (p *Poly768) GoString() string {
+	var b strings.Builder
+	b.WriteString("&karatsuba768.Poly768{")
+	for i := 0; i <= degree(p[:]); i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Itoa(int(p[i])))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Mul sets h to f*g, like Mul.
+func (h *Result1536) Mul(f, g *Poly768) {
+	Mul((*[1536]int32)(h), (*[768]int32)(f), (*[768]int32)(g))
+}
+
+// Coeff returns the coefficient of x^i in h.
+func (h *Result1536) Coeff(i int) int32 {
+	return h[i]
+}
+
+// Sum returns f(1), the sum of the coefficients of f, reduced modulo 9829.
+func Sum(f *[768]int32) int32 {
+	var s int64
+	for i := range f {
+		s += int64(f[i])
+	}
+	return Freeze(int32(s % 9829))
+}
+
+// SumResult returns h(1), the sum of the coefficients of h, reduced modulo
+// 9829. If h = f*g, then SumResult(h) = Freeze(Sum(f) * Sum(g)).
+func SumResult(h *[1536]int32) int32 {
+	var s int64
+	for i := range h {
+		s += int64(h[i])
+	}
+	return Freeze(int32(s % 9829))
+}
+
+// Add sets h to f + g, with coefficients reduced modulo 9829. h may alias f
+// or g.
+func Add(h, f, g *[768]int32) {
+	thinPoly(h[:]).Add(f[:], g[:])
+}
+
+// Sub sets h to f - g, with coefficients reduced modulo 9829. h may alias f
+// or g.
+func Sub(h, f, g *[768]int32) {
+	thinPoly(h[:]).Sub(f[:], g[:])
+}
+
+// Neg sets h to -f, with coefficients reduced modulo 9829, so that 0 maps to
+// 0 and x in [1, 9828] to 9829 - x. h may alias f.
+func Neg(h, f *[768]int32) {
+	for i := range h {
+		h[i] = Freeze(-f[i])
+	}
+}
+
+// Evaluate returns f(x) modulo 9829, computed by Horner's rule. The
+// coefficients of f must be in [0, 9828]. f fits in the L1 cache, so the order
+// in which it is read does not matter; Horner's rule takes half the
+// multiplications of summing f[i]*x^i, but they form a single dependency
+// chain, and both run at about the same speed.
+func Evaluate(f *[768]int32, x int32) int32 {
+	x = Freeze(x)
+	y := f[767]
+	for i := 766; i >= 0; i-- {
+		y = Freeze(y*x + f[i])
+	}
+	return y
+}
+
+// EvalAll sets ys[i] to f(xs[i]) modulo 9829, for every point in xs. ys must
+// be at least as long as xs. The points are evaluated four at a time, so that
+// the four Horner chains overlap; this is more than three times faster than
+// calling Evaluate on each point.
+func EvalAll(f *[768]int32, xs []int32, ys []int32) {
+	ys = ys[:len(xs)]
+	i := 0
+	for ; i+4 <= len(xs); i += 4 {
+		x0, x1, x2, x3 := Freeze(xs[i]), Freeze(xs[i+1]), Freeze(xs[i+2]), Freeze(xs[i+3])
+		y0, y1, y2, y3 := f[767], f[767], f[767], f[767]
+		for j := 766; j >= 0; j-- {
+			y0 = Freeze(y0*x0 + f[j])
+			y1 = Freeze(y1*x1 + f[j])
+			y2 = Freeze(y2*x2 + f[j])
+			y3 = Freeze(y3*x3 + f[j])
+		}
+		ys[i], ys[i+1], ys[i+2], ys[i+3] = y0, y1, y2, y3
+	}
+	for ; i < len(xs); i++ {
+		ys[i] = Evaluate(f, xs[i])
+	}
+}
+
+// Deriv sets df to the formal derivative of f, with df[i] = (i+1)*f[i+1]
+// modulo 9829 and df[767] = 0. The coefficients of f must be in [0, 9828];
+// the products are then at most 767*9828, well within the domain of Freeze,
+// so no wider arithmetic is needed. df may alias f.
+func Deriv(df, f *[768]int32) {
+	for i := 0; i < 767; i++ {
+		df[i] = Freeze(int32(i+1) * f[i+1])
+	}
+	df[767] = 0
+}
+
+// Validate returns an error if a coefficient of f is not in [0, 9828].
+func Validate(f *[768]int32) error {
+	for i, x := range f {
+		if x < 0 || x > 9828 {
+			return fmt.Errorf("coefficient %d out of range: %d", i, x)
+		}
+	}
+	return nil
+}
+
+// IsSmall reports whether every coefficient of f is 0, 1 or 9828, that is,
+// whether f is a ternary polynomial with coefficients in {-1, 0, 1}. It takes
+// the same time for any f, so it may be used to validate secret keys.
+func IsSmall(f *[768]int32) bool {
+	ok := 1
+	for _, x := range f {
+		ok &= subtle.ConstantTimeEq(x, 0) | subtle.ConstantTimeEq(x, 1) |
+			subtle.ConstantTimeEq(x, 9828)
+	}
+	return ok == 1
+}
+
+// Norm2 returns the squared Euclidean norm of f, the sum of the squares of its
+// coefficients, without reduction. It cannot overflow for coefficients of
+// absolute value below 2^26.
+func Norm2(f *[768]int32) int64 {
+	var n int64
+	for _, x := range f {
+		n += int64(x) * int64(x)
+	}
+	return n
+}
+
+// Norm2Mod returns Norm2(f) modulo 9829. The coefficients of f must be in
+// [0, 9828].
+func Norm2Mod(f *[768]int32) int32 {
+	var n int32
+	for _, x := range f {
+		n = Freeze(n + x*x)
+	}
+	return n
+}
+
+// Norm2Centered returns the squared Euclidean norm of f with its
+// coefficients, which must be in [0, 9828], taken in [-4914, 4914] instead.
+// This is the norm that matters for bounds on small polynomials: 9828 is -1,
+// not a large coefficient. Its running time does not depend on f.
+func Norm2Centered(f *[768]int32) int64 {
+	var n int64
+	for _, x := range f {
+		// subtract 9829 if x > 4914
+		x -= 9829 & ((4914 - x) >> 31)
+		n += int64(x) * int64(x)
+	}
+	return n
+}
+
+// CenteredPoly sets h to f with every coefficient reduced by CenteredFreeze,
+// into [-4914, 4914]. h may alias f. Its running time does not depend on f.
+func CenteredPoly(h, f *[768]int32) {
+	for i, x := range f {
+		h[i] = CenteredFreeze(x)
+	}
+}
+
+// InnerProduct returns the sum of f[i]*g[i] modulo 9829, which is
+// Freeze(Σ Freeze(f[i]*g[i])). The coefficients of f and g must be in
+// [0, 9828]. The products are summed unreduced in four independent int64
+// accumulators, which cannot overflow, and reduced once at the end.
+func InnerProduct(f, g *[768]int32) int32 {
+	var n0, n1, n2, n3 int64
+	for i := 0; i < 768; i += 4 {
+		x := (*[4]int32)(f[i : i+4])
+		y := (*[4]int32)(g[i : i+4])
+		n0 += int64(x[0]) * int64(y[0])
+		n1 += int64(x[1]) * int64(y[1])
+		n2 += int64(x[2]) * int64(y[2])
+		n3 += int64(x[3]) * int64(y[3])
+	}
+	return int32((n0 + n1 + n2 + n3) % 9829)
+}
+
+// Reduce3 sets out to f modulo 3, with coefficients in {0, 1, 2}, taking the
+// coefficients of f, which must be in [0, 9828], in [-4914, 4914] first, so
+// that 9828 maps to 2, that is, -1. out may alias f. Its running time does not
+// depend on f.
+func Reduce3(out *[768]int32, f *[768]int32) {
+	for i, x := range f {
+		// 4914 is a multiple of 3, so u = x + 4914 in [0, 9828] has the
+		// residue of the centered x; 21846/65536 approximates 1/3 closely
+		// enough for u < 2^14
+		x -= 9829 & ((4914 - x) >> 31)
+		u := x + 4914
+		out[i] = u - 3*((u*21846)>>16)
+	}
+}
+
+// Lift3 sets out to f, whose coefficients must be in {0, 1, 2}, with 2 taken
+// as -1, modulo 9829, so that Reduce3 undoes it. out may alias f. Its running
+// time does not depend on f.
+func Lift3(out *[768]int32, f *[768]int32) {
+	for i, x := range f {
+		out[i] = x + 9826*(x>>1)
+	}
+}
+
+// HammingWeight returns the number of nonzero coefficients of f, after
+// Freeze. The coefficients of f must be in the domain of Freeze. Its running
+// time does not depend on f, nor do those of PosWeight and NegWeight.
+func HammingWeight(f *[768]int32) int {
+	w := 0
+	for _, x := range f {
+		w += int(uint32(-Freeze(x)) >> 31)
+	}
+	return w
+}
+
+// PosWeight returns the number of coefficients of f that are positive in the
+// centered representation, that is, in [1, 4914] after Freeze.
+func PosWeight(f *[768]int32) int {
+	return HammingWeight(f) - NegWeight(f)
+}
+
+// NegWeight returns the number of coefficients of f that are negative in the
+// centered representation, that is, in [4915, 9828] after Freeze.
+func NegWeight(f *[768]int32) int {
+	w := 0
+	for _, x := range f {
+		w += int(uint32(4914-Freeze(x)) >> 31)
+	}
+	return w
+}
+
+// CountSmallPolysBelow returns the number of ternary polynomials of degree
+// less than 768 with exactly weight nonzero coefficients and a squared norm
+// below squaredNormBound, with coefficients taken in {-1, 0, 1}. The squared
+// norm of such a polynomial is its weight, so the count is either zero or
+// binomial(768, weight) * 2^weight; counts that do not fit in an int64 are
+// returned as math.MaxInt64.
+func CountSmallPolysBelow(squaredNormBound int64, weight int) int64 {
+	return countSmallPolysBelow(768, squaredNormBound, weight)
+}
+
+// countSmallPolysBelow is CountSmallPolysBelow for polynomials with n
+// coefficients.
+func countSmallPolysBelow(n int, squaredNormBound int64, weight int) int64 {
+	if weight < 0 || weight > n || int64(weight) >= squaredNormBound {
+		return 0
+	}
+	c := new(big.Int).Binomial(int64(n), int64(weight))
+	c.Lsh(c, uint(weight))
+	if !c.IsInt64() {
+		return math.MaxInt64
+	}
+	return c.Int64()
+}
+
+// Equal reports whether f and g have the same coefficients. The comparison
+// takes the same time regardless of where, or whether, the two polynomials
+// differ: the differences of all coefficients are ORed together before a
+// single test. A comparison that returns at the first difference, such as
+// *f == *g, must not be used on secret polynomials: its running time reveals
+// the length of the common prefix, which an attacker able to choose one of the
+// operands can use to recover the other.
+func Equal(f, g *[768]int32) bool {
+	return equalCT(f[:], g[:])
+}
+
+// ResultEqual is like Equal, for products of 1536 coefficients.
+func ResultEqual(f, g *[1536]int32) bool {
+	return equalCT(f[:], g[:])
+}
+
+// equalCT ORs together the differences of all coefficients and tests the
+// result once with subtle.ConstantTimeEq. It does not use
+// subtle.ConstantTimeCompare, which would need f and g serialized to bytes
+// first: the copy costs more than the comparison, and the XOR-OR loop over the
+// int32s is the same constant-time construction ConstantTimeCompare uses.
+func equalCT(f, g []int32) bool {
+	var v int32
+	for i := range f {
+		v |= f[i] ^ g[i]
+	}
+	return subtle.ConstantTimeEq(v, 0) == 1
+}
+
+// CSwap exchanges the coefficients of f and g if swap is 1, and leaves them
+// unchanged if swap is 0; swap must be 0 or 1. Both polynomials are read and
+// written in either case. With an if, the swap would run only when swap is 1:
+// the branch, and the memory writes it guards, would then reveal swap through
+// the running time and the state of the caches and the branch predictor,
+// which matters when swap is derived from a secret.
+func CSwap(f, g *[768]int32, swap int) {
+	cswapCT(f[:], g[:], swap)
+}
+
+// CSwapResult is like CSwap, for products of 1536 coefficients.
+func CSwapResult(f, g *[1536]int32, swap int) {
+	cswapCT(f[:], g[:], swap)
+}
+
+func cswapCT(f, g []int32, swap int) {
+	for i := range f {
+		x, y := int(f[i]), int(g[i])
+		f[i] = int32(subtle.ConstantTimeSelect(swap, y, x))
+		g[i] = int32(subtle.ConstantTimeSelect(swap, x, y))
+	}
+}
+
+// PolySelect sets out to a if v is 1 and to b if v is 0; v must be 0 or 1.
+// Every coefficient is chosen with subtle.ConstantTimeSelect, so neither the
+// branches nor the memory accesses depend on v, for the reasons given for
+// CSwap. See https://pkg.go.dev/crypto/subtle#ConstantTimeSelect. out may
+// alias a or b.
+func PolySelect(out, a, b *[768]int32, v int) {
+	selectCT(out[:], a[:], b[:], v)
+}
+
+// ResultSelect is like PolySelect, for products of 1536 coefficients.
+func ResultSelect(out, a, b *[1536]int32, v int) {
+	selectCT(out[:], a[:], b[:], v)
+}
+
+func selectCT(out, a, b []int32, v int) {
+	for i := range out {
+		out[i] = int32(subtle.ConstantTimeSelect(v, int(a[i]), int(b[i])))
+	}
+}
+
+// Zeroize sets every coefficient of f to zero, for wiping secrets such as a
+// private key once they are no longer needed. Unlike a plain loop at the end
+// of a caller, the stores cannot be removed by the compiler as dead, since
+// they are made by a function that is never inlined.
+//
+// Zeroize only clears f itself. Copies made earlier, whether by the caller,
+// by a stack that grew and moved, or by the garbage collector, are not
+// reached, and the Go runtime gives no way to prevent them; Zeroize limits
+// the exposure of a secret, it does not guarantee that no copy survives.
+func Zeroize(f *[768]int32) {
+	zeroize(f[:])
+}
+
+// ZeroizeResult is like Zeroize, for a product of 1536 coefficients.
+func ZeroizeResult(h *[1536]int32) {
+	zeroize(h[:])
+}
+
+//go:noinline
+func zeroize(p []int32) {
+	for i := range p {
+		p[i] = 0
+	}
+	runtime.KeepAlive(p)
+}
+
+// Equal reports whether p and other have the same coefficients, in constant
+// time. See the function Equal.
+func (p *Poly768) Equal(other *Poly768) bool {
+	return Equal((*[768]int32)(p), (*[768]int32)(other))
+}
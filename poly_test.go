@@ -0,0 +1,606 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"unsafe"
+)
+
+func randPoly(f *[768]int32) *[768]int32 {
+	for i := range f {
+		f[i] = int32(rand.Intn(9829))
+	}
+	return f
+}
+
+func TestPoly768(t *testing.T) {
+	f := NewPoly768()
+	if *f != (Poly768{}) {
+		t.Fatal("NewPoly768 is not zero")
+	}
+	f.SetCoeff(0, -1)
+	f.SetCoeff(767, 9829+5)
+	if f.Coeff(0) != 9828 || f.Coeff(767) != 5 {
+		t.Fatalf("f[0]=%d f[767]=%d", f.Coeff(0), f.Coeff(767))
+	}
+	for i := range f {
+		f[i] = int32(rand.Intn(2*9829)) - 9829
+	}
+	if err := Validate((*[768]int32)(f.Freeze())); err != nil {
+		t.Fatal(err)
+	}
+
+	g := (*Poly768)(randPoly(new([768]int32)))
+	h := new(Result1536)
+	h.Mul(f, g)
+	c := new([1536]int32)
+	textbookMul(c, (*[768]int32)(f), (*[768]int32)(g))
+	if err := cmpPoly(t, c, (*[1536]int32)(h)); err != nil {
+		t.Fatalf("c != h: %v", err)
+	}
+	if h.Coeff(1534) != c[1534] {
+		t.Fatalf("h[1534]=%d != c[1534]=%d", h.Coeff(1534), c[1534])
+	}
+}
+
+func TestPoly768String(t *testing.T) {
+	p := new(Poly768)
+	if s := p.String(); s != "Poly768[9829]{0}" {
+		t.Fatalf("zero: %s", s)
+	}
+	if s := fmt.Sprintf("%#v", p); s != "&karatsuba768.Poly768{}" {
+		t.Fatalf("zero: %s", s)
+	}
+	p[0], p[1], p[3] = 1, 2, 9828
+	if s := fmt.Sprint(p); s != "Poly768[9829]{1 + 2·x + 9828·x^3}" {
+		t.Fatalf("String: %s", s)
+	}
+	if s := fmt.Sprintf("%#v", p); s != "&karatsuba768.Poly768{1, 2, 0, 9828}" {
+		t.Fatalf("GoString: %s", s)
+	}
+	p[767] = 5
+	if s := p.String(); s != "Poly768[9829]{1 + 2·x + 9828·x^3 + ...}" {
+		t.Fatalf("String: %s", s)
+	}
+	p[0], p[1], p[3] = 0, 0, 0
+	if s := p.String(); s != "Poly768[9829]{...}" {
+		t.Fatalf("String: %s", s)
+	}
+}
+
+func TestSumResult(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		h := new([1536]int32)
+		Mul(h, f, g)
+		x := SumResult(h)
+		y := Freeze(Sum(f) * Sum(g))
+		if x != y {
+			t.Fatalf("x=%d != y=%d", x, y)
+		}
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([768]int32)
+	Add(h, f, g)
+	for i := range h {
+		if h[i] != (f[i]+g[i])%9829 {
+			t.Fatalf("h[%d]=%d for f[%d]=%d, g[%d]=%d", i, h[i], i, f[i], i, g[i])
+		}
+	}
+	Sub(h, h, g)
+	if *h != *f {
+		t.Fatal("f + g - g != f")
+	}
+	Sub(h, g, g)
+	if *h != [768]int32{} {
+		t.Fatal("g - g != 0")
+	}
+	Sub(h, h, &[768]int32{1})
+	if h[0] != 9828 {
+		t.Fatalf("0 - 1 = %d", h[0])
+	}
+}
+
+func TestSub(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		h := new([768]int32)
+		Add(h, f, g)
+		Sub(h, h, g)
+		if *h != *f {
+			t.Fatal("(f + g) - g != f")
+		}
+		p := make(thinPoly, 768).Sub(f[:], g[:])
+		for j := range p {
+			if p[j] < 0 || p[j] > 9828 || (p[j]+g[j])%9829 != f[j] {
+				t.Fatalf("p[%d]=%d for f[%d]=%d, g[%d]=%d", j, p[j], j, f[j], j, g[j])
+			}
+		}
+	}
+}
+
+func TestNegInvolutory(t *testing.T) {
+	f := randPoly(new([768]int32))
+	f[0], f[1] = 0, 9828
+	h := new([768]int32)
+	Neg(h, f)
+	if h[0] != 0 || h[1] != 1 {
+		t.Fatalf("-0=%d, -9828=%d", h[0], h[1])
+	}
+	Neg(h, h)
+	if *h != *f {
+		t.Fatal("-(-f) != f")
+	}
+}
+
+func TestNegAdd(t *testing.T) {
+	f := randPoly(new([768]int32))
+	h := new([768]int32)
+	Neg(h, f)
+	Add(h, f, h)
+	if *h != [768]int32{} {
+		t.Fatal("f + (-f) != 0")
+	}
+}
+
+// evaluateNaive evaluates f at x from the lowest degree up, keeping track of
+// the powers of x.
+func evaluateNaive(f *[768]int32, x int32) int32 {
+	x = Freeze(x)
+	y, p := int32(0), int32(1)
+	for i := range f {
+		y = Freeze(y + f[i]*p)
+		p = Freeze(p * x)
+	}
+	return y
+}
+
+func TestEvaluate(t *testing.T) {
+	f := randPoly(new([768]int32))
+	for _, x := range []int32{0, 1, 2, 9828, -1, int32(rand.Intn(9829))} {
+		if y, z := Evaluate(f, x), evaluateNaive(f, x); y != z {
+			t.Fatalf("y=%d != z=%d for x=%d", y, z, x)
+		}
+	}
+	if y := Evaluate(f, 1); y != Sum(f) {
+		t.Fatalf("f(1)=%d != Sum(f)=%d", y, Sum(f))
+	}
+	if y := Evaluate(f, 0); y != f[0] {
+		t.Fatalf("f(0)=%d != f[0]=%d", y, f[0])
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	f := randPoly(new([768]int32))
+	for i := 0; i < b.N; i++ {
+		Evaluate(f, 4321)
+	}
+}
+
+func BenchmarkEvaluateNaive(b *testing.B) {
+	f := randPoly(new([768]int32))
+	for i := 0; i < b.N; i++ {
+		evaluateNaive(f, 4321)
+	}
+}
+
+func TestEvalAll(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	Mul(h, f, g)
+	hi, lo := new([768]int32), new([768]int32)
+	copy(lo[:], h[:768])
+	copy(hi[:], h[768:])
+	xs := []int32{0, 1, -1, 9828, 2, 4321, int32(rand.Intn(9829))}
+	ys := make([]int32, len(xs))
+	EvalAll(f, xs, ys)
+	for i, x := range xs {
+		if ys[i] != Evaluate(f, x) {
+			t.Fatalf("EvalAll gives %d != %d for x=%d", ys[i], Evaluate(f, x), x)
+		}
+		// h(x) = lo(x) + x^768 hi(x)
+		x768 := int32(1)
+		for j := 0; j < 768; j++ {
+			x768 = Freeze(x768 * Freeze(x))
+		}
+		hx := Freeze(Evaluate(lo, x) + Freeze(x768*Evaluate(hi, x)))
+		if fg := Freeze(Evaluate(f, x) * Evaluate(g, x)); hx != fg {
+			t.Fatalf("h(x)=%d != f(x)g(x)=%d for x=%d", hx, fg, x)
+		}
+	}
+}
+
+func BenchmarkEvalAll(b *testing.B) {
+	f := randPoly(new([768]int32))
+	xs := []int32{1, 2, 3, 4, 5, 6, 7, 8}
+	ys := make([]int32, len(xs))
+	for i := 0; i < b.N; i++ {
+		EvalAll(f, xs, ys)
+	}
+}
+
+func TestDerivLinearity(t *testing.T) {
+	f, g, h := new([768]int32), new([768]int32), new([768]int32)
+	df, dg, dh := new([768]int32), new([768]int32), new([768]int32)
+	for i := 0; i < 8; i++ {
+		randPoly(f)
+		randPoly(g)
+		a, b := int32(rand.Intn(9829)), int32(rand.Intn(9829))
+		for j := range h {
+			h[j] = Freeze(Freeze(a*f[j]) + Freeze(b*g[j]))
+		}
+		Deriv(df, f)
+		Deriv(dg, g)
+		Deriv(dh, h)
+		for j := range dh {
+			if want := Freeze(Freeze(a*df[j]) + Freeze(b*dg[j])); dh[j] != want {
+				t.Fatalf("coefficient %d of (af+bg)' is %d, want %d", j, dh[j], want)
+			}
+		}
+
+		// in place
+		copy(h[:], f[:])
+		Deriv(h, h)
+		if !Equal(h, df) {
+			t.Fatal("Deriv in place differs")
+		}
+	}
+
+	// (x^k)' = k*x^(k-1)
+	for _, k := range []int{1, 2, 767} {
+		f = new([768]int32)
+		f[k] = 9828
+		Deriv(df, f)
+		want := new([768]int32)
+		want[k-1] = Freeze(int32(-k))
+		if !Equal(df, want) {
+			t.Fatalf("wrong derivative of -x^%d", k)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f := randPoly(new([768]int32))
+	if err := Validate(f); err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []int32{-1, 9829, -165191049, 165191049} {
+		f[767] = x
+		if err := Validate(f); err == nil {
+			t.Fatalf("Validate accepted %d", x)
+		}
+	}
+}
+
+func TestIsSmall(t *testing.T) {
+	f := new([768]int32)
+	if !IsSmall(f) {
+		t.Fatal("IsSmall rejected zero")
+	}
+	for i := range f {
+		f[i] = []int32{0, 1, 9828}[i%3]
+	}
+	if !IsSmall(f) {
+		t.Fatal("IsSmall rejected a ternary polynomial")
+	}
+	for _, x := range []int32{-1, 2, 9827, 9829} {
+		f[500] = x
+		if IsSmall(f) {
+			t.Fatalf("IsSmall accepted %d", x)
+		}
+	}
+}
+
+func TestNorm2(t *testing.T) {
+	f := new([768]int32)
+	f[0], f[1], f[2], f[3], f[767] = 1, 9828, 4914, 4915, 2
+	if n := Norm2(f); n != 1+9828*9828+4914*4914+4915*4915+4 {
+		t.Fatalf("Norm2=%d", n)
+	}
+	if n := Norm2Centered(f); n != 1+1+4914*4914+4914*4914+4 {
+		t.Fatalf("Norm2Centered=%d", n)
+	}
+	for i := 0; i < 8; i++ {
+		f = randPoly(f)
+		if n, m := Norm2Mod(f), int32(Norm2(f)%9829); n != m {
+			t.Fatalf("Norm2Mod=%d != %d", n, m)
+		}
+		if n, m := Norm2Centered(f)%9829, Norm2(f)%9829; n != m {
+			t.Fatalf("Norm2Centered=%d != Norm2=%d modulo 9829", n, m)
+		}
+	}
+}
+
+func TestInnerProductBilinear(t *testing.T) {
+	a, b, c, s := new([768]int32), new([768]int32), new([768]int32), new([768]int32)
+	for i := 0; i < 8; i++ {
+		randPoly(a)
+		randPoly(b)
+		randPoly(c)
+		Add(s, a, b)
+		if x, y := InnerProduct(s, c), Freeze(InnerProduct(a, c)+InnerProduct(b, c)); x != y {
+			t.Fatalf("<a+b, c>=%d, <a, c>+<b, c>=%d", x, y)
+		}
+		if x, y := InnerProduct(c, s), InnerProduct(s, c); x != y {
+			t.Fatalf("<c, a+b>=%d, <a+b, c>=%d", x, y)
+		}
+		if x, y := InnerProduct(a, a), Norm2Mod(a); x != y {
+			t.Fatalf("<a, a>=%d, Norm2Mod(a)=%d", x, y)
+		}
+		var want int32
+		for j := range a {
+			want = Freeze(want + Freeze(a[j]*c[j]))
+		}
+		if x := InnerProduct(a, c); x != want {
+			t.Fatalf("<a, c>=%d, want %d", x, want)
+		}
+	}
+	for i := range a {
+		a[i], b[i] = 9828, 9828
+	}
+	if x := InnerProduct(a, b); x != 768 {
+		t.Fatalf("<-1, -1>=%d, want 768", x)
+	}
+}
+
+func TestReduce3(t *testing.T) {
+	f := new([768]int32)
+	for x := int32(0); x < 9829; x++ {
+		f[x%768] = x
+		Reduce3(f, f)
+		c := x
+		if c > 4914 {
+			c -= 9829
+		}
+		if want := (c%3 + 3) % 3; f[x%768] != want {
+			t.Fatalf("Reduce3 maps %d to %d, not %d", x, f[x%768], want)
+		}
+	}
+}
+
+func TestLift3RoundTrip(t *testing.T) {
+	f := new([768]int32)
+	for i := range f {
+		f[i] = int32(rand.Intn(3))
+	}
+	f[0], f[1], f[2] = 0, 1, 2
+	g := new([768]int32)
+	Lift3(g, f)
+	if g[0] != 0 || g[1] != 1 || g[2] != 9828 {
+		t.Fatalf("Lift3 maps 0, 1, 2 to %d, %d, %d", g[0], g[1], g[2])
+	}
+	h := new([768]int32)
+	Reduce3(h, g)
+	if *h != *f {
+		t.Fatal("Reduce3(Lift3(f)) != f")
+	}
+}
+
+func TestHammingWeightTernary(t *testing.T) {
+	for _, d := range []int{0, 1, 100, 255, 383} {
+		// d+1 ones and d minus ones, at random positions
+		f := new([768]int32)
+		perm := rand.Perm(768)
+		for i := 0; i <= d; i++ {
+			f[perm[i]] = 1
+		}
+		for i := d + 1; i <= 2*d; i++ {
+			f[perm[i]] = -1
+		}
+		if w := HammingWeight(f); w != 2*d+1 {
+			t.Fatalf("HammingWeight=%d != %d", w, 2*d+1)
+		}
+		if w := PosWeight(f); w != d+1 {
+			t.Fatalf("PosWeight=%d != %d", w, d+1)
+		}
+		if w := NegWeight(f); w != d {
+			t.Fatalf("NegWeight=%d != %d", w, d)
+		}
+	}
+
+	f := new([768]int32)
+	f[0], f[1], f[2], f[3], f[4] = 9829, 4914, 4915, 9828, -9829
+	if w, p, n := HammingWeight(f), PosWeight(f), NegWeight(f); w != 3 || p != 1 || n != 2 {
+		t.Fatalf("weights %d, %d, %d != 3, 1, 2", w, p, n)
+	}
+}
+
+func TestCountSmallPolysBelow(t *testing.T) {
+	// brute force over all ternary polynomials with 8 coefficients
+	const n, w = 8, 2
+	counts := make(map[int64]int64)
+	for v := 0; v < 6561; v++ {
+		weight, norm := 0, int64(0)
+		for i, x := 0, v; i < n; i, x = i+1, x/3 {
+			c := int64([]int{0, 1, -1}[x%3])
+			if c != 0 {
+				weight++
+			}
+			norm += c * c
+		}
+		if weight == w {
+			counts[norm]++
+		}
+	}
+	for bound := int64(0); bound <= 4; bound++ {
+		var want int64
+		for norm, c := range counts {
+			if norm < bound {
+				want += c
+			}
+		}
+		if got := countSmallPolysBelow(n, bound, w); got != want {
+			t.Fatalf("got %d != want %d for bound=%d", got, want, bound)
+		}
+	}
+
+	if c := CountSmallPolysBelow(2, 1); c != 768*2 {
+		t.Fatalf("c=%d for w=1", c)
+	}
+	if c := CountSmallPolysBelow(1000, 286); c != math.MaxInt64 {
+		t.Fatalf("c=%d for w=286", c)
+	}
+	if c := CountSmallPolysBelow(1000, 769); c != 0 {
+		t.Fatalf("c=%d for w=769", c)
+	}
+}
+
+func TestPolyEqual(t *testing.T) {
+	p := (*Poly768)(randPoly(new([768]int32)))
+	q := new(Poly768)
+	*q = *p
+	if !p.Equal(q) {
+		t.Fatal("p != q")
+	}
+	for _, i := range []int{0, 383, 767} {
+		q[i] ^= 1 << 16
+		if p.Equal(q) {
+			t.Fatalf("p == q after changing i=%d", i)
+		}
+		q[i] = p[i]
+	}
+}
+
+// TestEqualConstantTime checks that a difference in any bit of any
+// coefficient survives the accumulation, so that Equal cannot rely on
+// comparing some coefficients or bits only.
+func TestEqualConstantTime(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := new([768]int32)
+	*g = *f
+	if !Equal(f, g) {
+		t.Fatal("f != g")
+	}
+	for i := range g {
+		for b := uint(0); b < 32; b++ {
+			g[i] ^= 1 << b
+			if Equal(f, g) {
+				t.Fatalf("f == g after flipping bit %d of coefficient %d", b, i)
+			}
+			g[i] = f[i]
+		}
+	}
+
+	h := new([1536]int32)
+	k := new([1536]int32)
+	Mul(h, f, f)
+	*k = *h
+	if !ResultEqual(h, k) {
+		t.Fatal("h != k")
+	}
+	for _, i := range []int{0, 767, 768, 1535} {
+		k[i] ^= -1 << 31
+		if ResultEqual(h, k) {
+			t.Fatalf("h == k after changing coefficient %d", i)
+		}
+		k[i] = h[i]
+	}
+}
+
+func TestPolySelectZero(t *testing.T) {
+	a := randPoly(new([768]int32))
+	b := randPoly(new([768]int32))
+	out := new([768]int32)
+	PolySelect(out, a, b, 0)
+	if *out != *b {
+		t.Fatal("PolySelect(v=0) is not b")
+	}
+	ha, hb, hout := new([1536]int32), new([1536]int32), new([1536]int32)
+	randPolyQ(ha[:], 9829)
+	randPolyQ(hb[:], 9829)
+	ResultSelect(hout, ha, hb, 0)
+	if *hout != *hb {
+		t.Fatal("ResultSelect(v=0) is not b")
+	}
+	PolySelect(a, a, b, 0)
+	if *a != *b {
+		t.Fatal("PolySelect(v=0) with out aliasing a is not b")
+	}
+}
+
+func TestPolySelectOne(t *testing.T) {
+	a := randPoly(new([768]int32))
+	b := randPoly(new([768]int32))
+	out := new([768]int32)
+	PolySelect(out, a, b, 1)
+	if *out != *a {
+		t.Fatal("PolySelect(v=1) is not a")
+	}
+	ha, hb, hout := new([1536]int32), new([1536]int32), new([1536]int32)
+	randPolyQ(ha[:], 9829)
+	randPolyQ(hb[:], 9829)
+	ResultSelect(hout, ha, hb, 1)
+	if *hout != *ha {
+		t.Fatal("ResultSelect(v=1) is not a")
+	}
+	c := *a
+	PolySelect(b, a, b, 1)
+	if *b != c {
+		t.Fatal("PolySelect(v=1) with out aliasing b is not a")
+	}
+}
+
+func TestZeroize(t *testing.T) {
+	f := randPoly(new([768]int32))
+	f[0], f[767] = 1, 1
+	Zeroize(f)
+	mem := unsafe.Slice((*byte)(unsafe.Pointer(f)), unsafe.Sizeof(*f))
+	for i, b := range mem {
+		if b != 0 {
+			t.Fatalf("byte %d of f is %#x after Zeroize", i, b)
+		}
+	}
+
+	h := new([1536]int32)
+	for i := range h {
+		h[i] = -1
+	}
+	ZeroizeResult(h)
+	mem = unsafe.Slice((*byte)(unsafe.Pointer(h)), unsafe.Sizeof(*h))
+	for i, b := range mem {
+		if b != 0 {
+			t.Fatalf("byte %d of h is %#x after ZeroizeResult", i, b)
+		}
+	}
+}
+
+func TestCSwapCorrectness(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	f0, g0 := *f, *g
+	CSwap(f, g, 0)
+	if *f != f0 || *g != g0 {
+		t.Fatal("CSwap with swap=0 changed f or g")
+	}
+	CSwap(f, g, 1)
+	if *f != g0 || *g != f0 {
+		t.Fatal("CSwap with swap=1 did not exchange f and g")
+	}
+
+	h := new([1536]int32)
+	k := new([1536]int32)
+	Mul(h, f, g)
+	h[1535], k[0] = -1, 1<<31-1
+	h0, k0 := *h, *k
+	CSwapResult(h, k, 0)
+	if *h != h0 || *k != k0 {
+		t.Fatal("CSwapResult with swap=0 changed h or k")
+	}
+	CSwapResult(h, k, 1)
+	if *h != k0 || *k != h0 {
+		t.Fatal("CSwapResult with swap=1 did not exchange h and k")
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "fmt"
+
+// PolyVec is a vector of elements of Z_9829[x]/(x^768 - x - 1), the ring of
+// MulMod.
+type PolyVec []*Poly768
+
+// InnerProduct sets h to the sum of v[i]*u[i], computed with MulMod and Add.
+// An error is returned, and h is left untouched, if v and u do not have the
+// same length or if one of their elements is nil. h may alias an element of v
+// or u. The inner product of two empty vectors is zero.
+func (v PolyVec) InnerProduct(u PolyVec, h *[768]int32) error {
+	if len(v) != len(u) {
+		return fmt.Errorf("vectors of length %d and %d", len(v), len(u))
+	}
+	for i := range v {
+		if v[i] == nil || u[i] == nil {
+			return fmt.Errorf("element %d is nil", i)
+		}
+	}
+	s := new([768]int32)
+	t := new([768]int32)
+	for i := range v {
+		MulMod(t, (*[768]int32)(v[i]), (*[768]int32)(u[i]))
+		Add(s, s, t)
+	}
+	*h = *s
+	return nil
+}
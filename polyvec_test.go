@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func randPolyVec(n int) PolyVec {
+	v := make(PolyVec, n)
+	for i := range v {
+		v[i] = (*Poly768)(randPoly(new([768]int32)))
+	}
+	return v
+}
+
+func TestPolyVecInnerProductDimension(t *testing.T) {
+	h := new([768]int32)
+	h[0] = 5
+	if err := randPolyVec(3).InnerProduct(randPolyVec(2), h); err == nil {
+		t.Fatal("InnerProduct accepted vectors of length 3 and 2")
+	}
+	v := randPolyVec(2)
+	v[1] = nil
+	if err := v.InnerProduct(randPolyVec(2), h); err == nil {
+		t.Fatal("InnerProduct accepted a nil element")
+	}
+	if h[0] != 5 {
+		t.Fatal("h modified on error")
+	}
+	if err := PolyVec(nil).InnerProduct(PolyVec{}, h); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(h, new([768]int32)) {
+		t.Fatal("inner product of empty vectors is not zero")
+	}
+}
+
+func TestPolyVecInnerProductCorrectness(t *testing.T) {
+	for n := 1; n <= 4; n++ {
+		v, u := randPolyVec(n), randPolyVec(n)
+		want := new([768]int32)
+		p := new([768]int32)
+		for i := range v {
+			MulMod(p, (*[768]int32)(v[i]), (*[768]int32)(u[i]))
+			for j := range want {
+				want[j] = Freeze(want[j] + p[j])
+			}
+		}
+		h := new([768]int32)
+		if err := v.InnerProduct(u, h); err != nil {
+			t.Fatal(err)
+		}
+		if !Equal(h, want) {
+			t.Fatalf("wrong inner product for n=%d", n)
+		}
+
+		// h aliasing an element of v
+		if err := v.InnerProduct(u, (*[768]int32)(v[0])); err != nil {
+			t.Fatal(err)
+		}
+		if !Equal((*[768]int32)(v[0]), want) {
+			t.Fatalf("wrong inner product for n=%d with h aliasing v[0]", n)
+		}
+	}
+}
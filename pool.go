@@ -0,0 +1,22 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "sync"
+
+// The workspaces of Mul and MulMod are the only allocations left on the path
+// of a multiplication: the Karatsuba levels, toomEval and Toom6 carve their
+// intermediate values out of the workspace they are given. Mul and MulMod
+// borrow theirs from these pools, so that concurrent callers reuse a handful
+// of workspaces instead of allocating one per call. The pools hold pointers
+// to arrays, which, unlike slices, do not allocate when put back.
+var (
+	mulPool = sync.Pool{
+		New: func() any { return new([MulWorkspaceSize]int32) },
+	}
+	mulModPool = sync.Pool{
+		New: func() any { return new([MulModWorkspaceSize]int32) },
+	}
+)
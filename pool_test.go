@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+// TestMulPoolDirty checks that Mul does not depend on the contents of the
+// workspaces it borrows.
+func TestMulPoolDirty(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		ws := mulPool.Get().(*[MulWorkspaceSize]int32)
+		for j := range ws {
+			ws[j] = int32(j*7919) ^ -1
+		}
+		mulPool.Put(ws)
+
+		a := randPoly(new([768]int32))
+		b := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		textbookMul(c, a, b)
+		Mul(d, a, b)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d: %v", err)
+		}
+	}
+}
+
+// BenchmarkMulParallelNoPool runs Mul from all goroutines with a workspace
+// allocated per call, as Mul did before it used mulPool.
+func BenchmarkMulParallelNoPool(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		h := new([1536]int32)
+		for pb.Next() {
+			MulWithWorkspace(h, f, g, make([]int32, MulWorkspaceSize))
+		}
+	})
+}
+
+func BenchmarkMulParallelPool(b *testing.B) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		h := new([1536]int32)
+		for pb.Next() {
+			Mul(h, f, g)
+		}
+	})
+}
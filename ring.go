@@ -0,0 +1,672 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+)
+
+// ringModulus is a monic modulus x^768 + m(x), stored as the nonzero terms of
+// m. The modulus is public, so reductions may depend on its sparsity.
+type ringModulus struct {
+	idx []int
+	val []int32
+}
+
+// x^768 - x - 1
+var ntruRing = &ringModulus{idx: []int{0, 1}, val: []int32{9828, 9828}}
+
+// monicModulus returns the ringModulus for r/r[768], so that reductions
+// modulo it compute in Z_9829[x]/(r(x)).
+func monicModulus(r *[769]int32) (*ringModulus, error) {
+	lc := Freeze(r[768])
+	if lc == 0 {
+		return nil, errors.New("ring modulus is not of degree 768")
+	}
+	inv := Ring768.z.inverse(lc)
+	m := new(ringModulus)
+	for i := 0; i < 768; i++ {
+		if v := Freeze(Freeze(r[i]) * inv); v != 0 {
+			m.idx = append(m.idx, i)
+			m.val = append(m.val, v)
+		}
+	}
+	return m, nil
+}
+
+// reduce reduces the product h modulo m and stores the result in out. The
+// coefficients of h must be in [0, 9828]; h is overwritten.
+func (m *ringModulus) reduce(out *[768]int32, h *[1536]int32) {
+	for k := 1534; k >= 768; k-- {
+		c := h[k]
+		for j, i := range m.idx {
+			h[k-768+i] = Freeze(h[k-768+i] - c*m.val[j])
+		}
+	}
+	copy(out[:], h[:768])
+}
+
+// mul sets h to f*g modulo m.
+func (m *ringModulus) mul(h, f, g *[768]int32) {
+	t := new([1536]int32)
+	Mul(t, f, g)
+	m.reduce(h, t)
+}
+
+// MulRing sets h to f*g in Z_9829[x]/(r(x)), where r is the degree 768
+// polynomial given by ringMod. The leading coefficient of r must be
+// invertible modulo 9829, otherwise an error is returned. h may alias f or g.
+func MulRing(h *[768]int32, f, g *[768]int32, ringMod *[769]int32) error {
+	m, err := monicModulus(ringMod)
+	if err != nil {
+		return err
+	}
+	m.mul(h, f, g)
+	return nil
+}
+
+// MulModWorkspaceSize is the number of coefficients of the workspace taken
+// by MulModWithWorkspace: the 1536-coefficient product, followed by the
+// workspace of MulWithWorkspace.
+const MulModWorkspaceSize = 1536 + MulWorkspaceSize
+
+// MulMod sets h to f*g in Z_9829[x]/(x^768 - x - 1). h may alias f or g.
+func MulMod(h *[768]int32, f, g *[768]int32) {
+	ws := mulModPool.Get().(*[MulModWorkspaceSize]int32)
+	MulModWithWorkspace(h, f, g, ws[:])
+	mulModPool.Put(ws)
+}
+
+// MulModWithWorkspace is like MulMod, but it takes all of its intermediate
+// values from ws, which must have at least MulModWorkspaceSize elements.
+func MulModWithWorkspace(h *[768]int32, f, g *[768]int32, ws []int32) {
+	if len(ws) < MulModWorkspaceSize {
+		panic("karatsuba768: workspace too small")
+	}
+	t := (*[1536]int32)(ws[:1536])
+	MulWithWorkspace(t, f, g, ws[1536:])
+
+	// x^768 = x + 1; t[1535] is zero, and t[768:] receives nothing
+	for k := 1534; k >= 768; k-- {
+		t[k-768] += t[k]
+		t[k-767] += t[k]
+	}
+	copy(h[:], thinPoly(t[:768]).Freeze())
+}
+
+// InvertMod sets finv to the inverse of f in Z_9829[x]/(x^768 - x - 1) and
+// returns nil, or returns an error and leaves finv untouched if f is not
+// invertible. The product finv*f computed by MulMod is then 1. Its running time
+// depends on f.
+func InvertMod(finv *[768]int32, f *[768]int32) error {
+	return Ring768.Inv(finv[:], f[:])
+}
+
+// MulChain sets result to the product of all polynomials in factors in
+// Z_9829[x]/(r(x)), where r is given by ringMod. An empty chain yields the
+// identity polynomial 1, and a chain of one element yields a copy of it.
+// The factors are multiplied pairwise, level by level, so that a chain whose
+// length is a power of two is evaluated as a balanced binary tree.
+func MulChain(result *[768]int32, factors []*[768]int32, ringMod *[769]int32) error {
+	m, err := monicModulus(ringMod)
+	if err != nil {
+		return err
+	}
+
+	if len(factors) == 0 {
+		*result = [768]int32{}
+		result[0] = 1
+		return nil
+	}
+
+	level := make([]*[768]int32, len(factors))
+	for i := range factors {
+		level[i] = new([768]int32)
+		*level[i] = *factors[i]
+	}
+	for len(level) > 1 {
+		next := level[:0]
+		for i := 0; i+1 < len(level); i += 2 {
+			m.mul(level[i], level[i], level[i+1])
+			next = append(next, level[i])
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+	*result = *level[0]
+
+	return nil
+}
+
+// FoldCyclic reduces h modulo x^768 - 1, setting out[i] to h[i] + h[i+768]
+// modulo 9829. If h = f*g, out is the cyclic convolution of f and g.
+func FoldCyclic(out *[768]int32, h *[1536]int32) {
+	for i := range out {
+		out[i] = Freeze(h[i] + h[i+768])
+	}
+}
+
+// CyclicMul sets h to f*g in Z_9829[x]/(x^768 - 1), the cyclic convolution
+// of f and g. h may alias f or g.
+func CyclicMul(h *[768]int32, f, g *[768]int32) {
+	t := new([1536]int32)
+	Mul(t, f, g)
+	FoldCyclic(h, t)
+}
+
+// NegCyclicMul sets h to f*g in Z_9829[x]/(x^768 + 1), the negacyclic
+// convolution of f and g: coefficients that exceed x^767 wrap around with
+// their sign flipped. h may alias f or g.
+func NegCyclicMul(h *[768]int32, f, g *[768]int32) {
+	t := new([1536]int32)
+	Mul(t, f, g)
+	for i := range h {
+		h[i] = Freeze(t[i] - t[i+768])
+	}
+}
+
+// AlgebraicHash returns f(a)(1), the sum of the coefficients of the ring
+// element f(a) = f[0] + f[1]*a + ... + f[767]*a^767 of Z_9829[x]/(x^768-x-1).
+// The hash is linear in f, so that AlgebraicHash(f+g, a) equals
+// AlgebraicHash(f, a) + AlgebraicHash(g, a) modulo 9829. It is not a
+// cryptographic hash. The composition is evaluated with the Paterson-Stockmeyer
+// method, which takes 56 ring multiplications instead of the 767 of Horner's
+// rule.
+func AlgebraicHash(f *[768]int32, a *[768]int32) int32 {
+	const k = 28 // ceil(sqrt(768))
+
+	// a^0, ..., a^k
+	pow := make([]*[768]int32, k+1)
+	pow[0] = &[768]int32{1}
+	for i := 1; i <= k; i++ {
+		pow[i] = new([768]int32)
+		ntruRing.mul(pow[i], pow[i-1], a)
+	}
+
+	// f(a) = sum_j (sum_i f[jk+i]*a^i) * (a^k)^j, by Horner's rule in a^k
+	y := new([768]int32)
+	for j := (768 - 1) / k; j >= 0; j-- {
+		ntruRing.mul(y, y, pow[k])
+		for i := 0; i < k && j*k+i < 768; i++ {
+			c := Freeze(f[j*k+i])
+			for t := range y {
+				y[t] = Freeze(y[t] + c*pow[i][t])
+			}
+		}
+	}
+
+	return Sum(y)
+}
+
+// Ring is the quotient ring Z_q[x]/(r(x)), for a prime q and a polynomial r
+// of degree N whose leading coefficient is invertible modulo q. Its elements
+// are represented as slices of N coefficients in [0, Q-1]. A Ring must be
+// created with NewRing or NewRingParams, and its fields must not be modified
+// afterwards.
+//
+// Multiplication uses Mul when N = 768 and Q = 9829. Otherwise, it uses a
+// Karatsuba multiplication over the integers, whose recursion stops at
+// blocks of at most 32 coefficients, followed by a reduction modulo Q.
+type Ring struct {
+	Q   int32   // characteristic of the coefficient field
+	N   int     // degree of the modulus
+	Mod []int32 // modulus r, with N+1 coefficients in [0, Q-1]
+
+	z     zq
+	idx   []int   // nonzero terms of the monic modulus x^N + m(x), below x^N
+	val   []int32 // and their coefficients
+	leaf  int     // size of the blocks multiplied by schoolbook
+	depth int     // levels of Karatsuba above the blocks
+}
+
+// The quotient rings Z_q[x]/(x^p - x - 1) of the NTRU Prime parameter sets
+// sntrup653 to sntrup1277, and the ring of the same form for the 768 x 768
+// multiplication implemented by this package.
+var (
+	Ring653  = mustNTRUPrimeRing(653, 4621)
+	Ring761  = mustNTRUPrimeRing(761, 4591)
+	Ring768  = mustNTRUPrimeRing(768, 9829)
+	Ring857  = mustNTRUPrimeRing(857, 5167)
+	Ring953  = mustNTRUPrimeRing(953, 6343)
+	Ring1013 = mustNTRUPrimeRing(1013, 7177)
+	Ring1277 = mustNTRUPrimeRing(1277, 7879)
+)
+
+func mustNTRUPrimeRing(p int, q int32) *Ring {
+	r := make([]int32, p+1)
+	r[0], r[1], r[p] = -1, -1, 1
+	ring, err := NewRingParams(q, r)
+	if err != nil {
+		panic(err)
+	}
+	return ring
+}
+
+// NewRing returns the ring Z_9829[x]/(r(x)), where r is given by ringMod.
+func NewRing(ringMod *[769]int32) (*Ring, error) {
+	return NewRingParams(9829, ringMod[:])
+}
+
+// NewRingParams returns the ring Z_q[x]/(r(x)), where q is an odd prime below
+// 2^15 and r is a polynomial of degree len(r)-1, given by its coefficients.
+func NewRingParams(q int32, r []int32) (*Ring, error) {
+	z, err := newZq(q)
+	if err != nil {
+		return nil, err
+	}
+	n := len(r) - 1
+	if n < 1 {
+		return nil, errors.New("ring modulus is of degree 0")
+	}
+	ring := &Ring{Q: q, N: n, Mod: make([]int32, n+1), z: z}
+	for i := range r {
+		ring.Mod[i] = z.freeze(r[i])
+	}
+	if ring.Mod[n] == 0 {
+		return nil, fmt.Errorf("ring modulus is not of degree %d", n)
+	}
+	inv := z.inverse(ring.Mod[n])
+	for i := 0; i < n; i++ {
+		if v := z.freeze(ring.Mod[i] * inv); v != 0 {
+			ring.idx = append(ring.idx, i)
+			ring.val = append(ring.val, v)
+		}
+	}
+	ring.leaf = n
+	for ring.leaf > 32 {
+		ring.leaf = (ring.leaf + 1) / 2
+		ring.depth++
+	}
+	return ring, nil
+}
+
+// New returns the zero element.
+func (ring *Ring) New() []int32 {
+	return make([]int32, ring.N)
+}
+
+// Freeze reduces every coefficient of f modulo Q.
+func (ring *Ring) Freeze(f []int32) {
+	for i := range f {
+		f[i] = ring.z.freeze(f[i])
+	}
+}
+
+// Add sets h to f + g.
+func (ring *Ring) Add(h, f, g []int32) {
+	for i := 0; i < ring.N; i++ {
+		h[i] = ring.z.freeze(f[i] + g[i])
+	}
+}
+
+// Sub sets h to f - g.
+func (ring *Ring) Sub(h, f, g []int32) {
+	for i := 0; i < ring.N; i++ {
+		h[i] = ring.z.freeze(f[i] - g[i])
+	}
+}
+
+// Neg sets h to -f.
+func (ring *Ring) Neg(h, f []int32) {
+	for i := 0; i < ring.N; i++ {
+		h[i] = ring.z.freeze(-f[i])
+	}
+}
+
+// Mul sets h to f*g. h may alias f or g.
+func (ring *Ring) Mul(h, f, g []int32) {
+	n := ring.N
+	t := make([]int32, 2*n)
+	if n == 768 && ring.Q == 9829 {
+		Mul((*[1536]int32)(t), (*[768]int32)(f[:768]), (*[768]int32)(g[:768]))
+	} else {
+		m := ring.leaf << uint(ring.depth)
+		a := make([]int64, m)
+		b := make([]int64, m)
+		c := make([]int64, 2*m)
+		for i := 0; i < n; i++ {
+			a[i], b[i] = int64(f[i]), int64(g[i])
+		}
+		karatsuba64(c, a, b, ring.leaf)
+		for i := range t {
+			t[i] = ring.z.reduce(uint64(c[i]))
+		}
+	}
+
+	// x^n = -m(x)
+	for k := 2*n - 1; k >= n; k-- {
+		c := t[k]
+		for j, i := range ring.idx {
+			t[k-n+i] = ring.z.freeze(t[k-n+i] - c*ring.val[j])
+		}
+	}
+	copy(h[:n], t[:n])
+}
+
+// karatsuba64 sets h to f*g over the integers, where f and g have the same
+// length n and h has length 2n. n must be leaf times a power of two. The
+// coefficients of f and g must be nonnegative and small enough for those of
+// the product, and of the sums taken along the way, to fit in an int64.
+func karatsuba64(h, f, g []int64, leaf int) {
+	n := len(f)
+	if n <= leaf {
+		for i := range h {
+			h[i] = 0
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				h[i+j] += f[i] * g[j]
+			}
+		}
+		return
+	}
+
+	k := n / 2
+	f0, f1 := f[:k], f[k:]
+	g0, g1 := g[:k], g[k:]
+	a := make([]int64, k)
+	b := make([]int64, k)
+	m := make([]int64, 2*k)
+	for i := 0; i < k; i++ {
+		a[i], b[i] = f0[i]+f1[i], g0[i]+g1[i]
+	}
+	karatsuba64(h[:n], f0, g0, leaf)
+	karatsuba64(h[n:], f1, g1, leaf)
+	karatsuba64(m, a, b, leaf)
+	for i := range m {
+		m[i] -= h[i] + h[n+i]
+	}
+	for i := range m {
+		h[k+i] += m[i]
+	}
+}
+
+// Inv sets h to the inverse of f and returns nil, or returns an error and
+// leaves h untouched if f is not invertible. It uses the extended Euclidean
+// algorithm, whose running time depends on f.
+func (ring *Ring) Inv(h, f []int32) error {
+	t := ring.New()
+	if !invertPoly(t, f[:ring.N], ring.Mod, ring.z) {
+		return errors.New("polynomial is not invertible")
+	}
+	copy(h, t)
+	return nil
+}
+
+// Pow sets h to f^e, by square-and-multiply over the bits of e. Its running
+// time depends on e, but not on f.
+func (ring *Ring) Pow(h, f []int32, e uint64) {
+	b := ring.New()
+	copy(b, f)
+	r := ring.New()
+	r[0] = 1
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			ring.Mul(r, r, b)
+		}
+		ring.Mul(b, b, b)
+	}
+	copy(h, r)
+}
+
+// SampleUniform returns an element with coefficients drawn uniformly from
+// [0, Q-1] using bytes read from rand, which should be crypto/rand.Reader for
+// keys. Each coefficient is taken from the low bits of two bytes, as many as
+// there are in Q-1, rejecting values of Q or more. For Q = 9829, a draw is
+// accepted with probability 9829/16384, so about 1280 draws, or 512
+// rejections, are expected per element of 768 coefficients. rand is read two
+// bytes at a time, so no more bytes are taken from it than the draws consume.
+func (ring *Ring) SampleUniform(rand io.Reader) ([]int32, error) {
+	f := ring.New()
+	mask := int32(1)<<uint(bits.Len32(uint32(ring.Q-1))) - 1
+	var b [2]byte
+	for i := 0; i < ring.N; {
+		if _, err := io.ReadFull(rand, b[:]); err != nil {
+			return nil, err
+		}
+		x := (int32(b[0]) | int32(b[1])<<8) & mask
+		if x < ring.Q {
+			f[i] = x
+			i++
+		}
+	}
+	return f, nil
+}
+
+// SampleSmallWeight returns an element with exactly w nonzero coefficients,
+// each 1 or Q-1 (that is, -1), in uniformly random positions and with
+// uniformly random signs, as used for NTRU Prime private keys. The bytes are
+// read from rand, which should be crypto/rand.Reader. The positions are chosen
+// by a Fisher-Yates shuffle whose indices are drawn by rejection sampling from
+// 16-bit values; for N = 768 a draw is accepted with probability above 0.988,
+// and about 769 draws, or two rejections, are expected for the 767 indices.
+// Swaps scan the whole array, so the memory access pattern does not depend on
+// the positions. As with SampleUniform, only the bytes consumed are read.
+func (ring *Ring) SampleSmallWeight(w int, rand io.Reader) ([]int32, error) {
+	n := ring.N
+	if w < 0 || w > n {
+		return nil, fmt.Errorf("invalid weight %d", w)
+	}
+	var b [1]byte
+
+	f := ring.New()
+	for i := 0; i < w; i += 8 {
+		if _, err := io.ReadFull(rand, b[:]); err != nil {
+			return nil, err
+		}
+		for j := i; j < i+8 && j < w; j++ {
+			f[j] = 1 + (ring.Q-2)*int32(b[0]>>uint(j-i)&1)
+		}
+	}
+
+	if err := shuffleCT(f, rand); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// shuffleCT permutes f uniformly at random by a Fisher-Yates shuffle, with
+// indices drawn by rejection sampling from 16-bit values read from r, which
+// limits f to 65536 elements. Swaps scan the rest of f, so the memory access
+// pattern does not depend on the indices.
+func shuffleCT(f []int32, r io.Reader) error {
+	n := len(f)
+	var b [2]byte
+	for i := 0; i < n-1; i++ {
+		// j is uniform in [i, n-1]
+		m := uint32(n - i)
+		var j uint32
+		for {
+			if _, err := io.ReadFull(r, b[:]); err != nil {
+				return err
+			}
+			x := uint32(b[0]) | uint32(b[1])<<8
+			if x < 65536-65536%m {
+				j = uint32(i) + x%m
+				break
+			}
+		}
+		x := f[i]
+		var y int32
+		for k := i; k < n; k++ {
+			eq := subtle.ConstantTimeEq(int32(k), int32(j))
+			y = int32(subtle.ConstantTimeSelect(eq, int(f[k]), int(y)))
+			f[k] = int32(subtle.ConstantTimeSelect(eq, int(x), int(f[k])))
+		}
+		f[i] = y
+	}
+	return nil
+}
+
+// NewRandTernary sets f to a polynomial with exactly ones coefficients equal
+// to 1 and negones equal to 9828, that is, -1, in uniformly random positions,
+// the others being zero. The bytes are read from rng, which should be
+// crypto/rand.Reader. The positions are chosen as in Ring.SampleSmallWeight,
+// whose rejection sampling takes about two extra draws per polynomial, and
+// only the bytes consumed by the draws are read from rng. f is left untouched
+// on error.
+func NewRandTernary(f *[768]int32, ones, negones int, rng io.Reader) error {
+	if ones < 0 || negones < 0 || ones+negones > 768 {
+		return fmt.Errorf("invalid weights %d and %d", ones, negones)
+	}
+	t := new([768]int32)
+	for i := 0; i < ones; i++ {
+		t[i] = 1
+	}
+	for i := ones; i < ones+negones; i++ {
+		t[i] = 9828
+	}
+	if err := shuffleCT(t[:], rng); err != nil {
+		return err
+	}
+	*f = *t
+	return nil
+}
+
+// NewRandPoly sets f to a polynomial with coefficients uniformly random in
+// [0, q), where q must be in [2, 65536]. The bytes are read from rng, which
+// should be crypto/rand.Reader. Each coefficient is drawn by rejection
+// sampling from 16-bit values masked to the bit length of q-1, so that every
+// draw consumes exactly two bytes and at most half of them are rejected; the
+// number of draws depends only on the rejected values, not on f. f is left
+// untouched on error.
+func NewRandPoly(f *[768]int32, q int32, rng io.Reader) error {
+	if q < 2 || q > 65536 {
+		return fmt.Errorf("invalid modulus %d", q)
+	}
+	mask := uint32(1)<<bits.Len32(uint32(q-1)) - 1
+	t := new([768]int32)
+	var b [2]byte
+	for i := range t {
+		for {
+			if _, err := io.ReadFull(rng, b[:]); err != nil {
+				return err
+			}
+			x := (uint32(b[0]) | uint32(b[1])<<8) & mask
+			if x < uint32(q) {
+				t[i] = int32(x)
+				break
+			}
+		}
+	}
+	*f = *t
+	return nil
+}
+
+// Equal reports whether f and g are the same element, in constant time.
+func (ring *Ring) Equal(f, g []int32) bool {
+	var v int32
+	for i := 0; i < ring.N; i++ {
+		v |= f[i] ^ g[i]
+	}
+	return subtle.ConstantTimeEq(v, 0) == 1
+}
+
+// IsZero reports whether f is zero, in constant time.
+func (ring *Ring) IsZero(f []int32) bool {
+	return ring.Equal(f, ring.New())
+}
+
+// IsOne reports whether f is one, in constant time.
+func (ring *Ring) IsOne(f []int32) bool {
+	one := ring.New()
+	one[0] = 1
+	return ring.Equal(f, one)
+}
+
+// String returns a description of the ring such as
+// "Z_9829[x]/(x^768 + 9828*x + 9828)".
+func (ring *Ring) String() string {
+	var b strings.Builder
+	for i := ring.N; i >= 0; i-- {
+		c := ring.Mod[i]
+		if c == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(" + ")
+		}
+		switch {
+		case i == 0:
+			fmt.Fprintf(&b, "%d", c)
+		case c == 1:
+		default:
+			fmt.Fprintf(&b, "%d*", c)
+		}
+		switch {
+		case i == 1:
+			b.WriteString("x")
+		case i > 1:
+			fmt.Fprintf(&b, "x^%d", i)
+		}
+	}
+	return fmt.Sprintf("Z_%d[x]/(%s)", ring.Q, b.String())
+}
+
+// encodedBits returns the number of bits per coefficient used by Encode.
+func (ring *Ring) encodedBits() int {
+	return bits.Len32(uint32(ring.Q - 1))
+}
+
+// EncodedLen returns the length of the encoding of an element by Encode.
+func (ring *Ring) EncodedLen() int {
+	return (ring.N*ring.encodedBits() + 7) / 8
+}
+
+// Encode returns f packed into a little-endian bit string, with as many bits
+// per coefficient as there are in Q-1. For Ring768, this is 14 bits per
+// coefficient, in 1344 bytes.
+func (ring *Ring) Encode(f []int32) []byte {
+	buf := make([]byte, ring.EncodedLen())
+	nb := uint(ring.encodedBits())
+	var acc uint64
+	var n uint
+	j := 0
+	for i := 0; i < ring.N; i++ {
+		acc |= uint64(f[i]) << n
+		for n += nb; n >= 8; n -= 8 {
+			buf[j] = byte(acc)
+			acc >>= 8
+			j++
+		}
+	}
+	if n > 0 {
+		buf[j] = byte(acc)
+	}
+	return buf
+}
+
+// Decode decodes an element encoded by Encode. An error is returned if buf
+// is not EncodedLen bytes long or if a coefficient is not in [0, Q-1].
+func (ring *Ring) Decode(buf []byte) ([]int32, error) {
+	if len(buf) != ring.EncodedLen() {
+		return nil, errors.New("invalid length")
+	}
+	f := ring.New()
+	nb := uint(ring.encodedBits())
+	var acc uint64
+	var n uint
+	j := 0
+	for i := range f {
+		for ; n < nb; n += 8 {
+			acc |= uint64(buf[j]) << n
+			j++
+		}
+		f[i] = int32(acc & (1<<nb - 1))
+		acc >>= nb
+		n -= nb
+		if f[i] >= ring.Q {
+			return nil, fmt.Errorf("coefficient %d out of range: %d", i, f[i])
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,755 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// ntruModulus returns x^768 - x - 1.
+func ntruModulus() *[769]int32 {
+	r := new([769]int32)
+	r[0], r[1], r[768] = -1, -1, 1
+	return r
+}
+
+// textbookMulRing multiplies f and g modulo x^768 - x - 1.
+func textbookMulRing(h *[768]int32, f, g *[768]int32) {
+	c := new([1536]int32)
+	textbookMul(c, f, g)
+	for k := 1534; k >= 768; k-- {
+		c[k-768] = (c[k-768] + c[k]) % 9829
+		c[k-767] = (c[k-767] + c[k]) % 9829
+	}
+	copy(h[:], c[:768])
+}
+
+func TestInverse(t *testing.T) {
+	for x := int32(1); x < 9829; x++ {
+		if y := Freeze(x * Ring768.z.inverse(x)); y != 1 {
+			t.Fatalf("x*inverse(x)=%d for x=%d", y, x)
+		}
+	}
+}
+
+func TestMulRing(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		c := new([768]int32)
+		d := new([768]int32)
+		textbookMulRing(c, f, g)
+		if err := MulRing(d, f, g, ntruModulus()); err != nil {
+			t.Fatal(err)
+		}
+		if *c != *d {
+			t.Fatalf("c != d for i=%d", i)
+		}
+	}
+}
+
+func TestMulRingBadModulus(t *testing.T) {
+	r := ntruModulus()
+	r[768] = 9829
+	f := randPoly(new([768]int32))
+	if err := MulRing(f, f, f, r); err == nil {
+		t.Fatal("MulRing accepted a modulus of degree < 768")
+	}
+}
+
+func TestMulMod(t *testing.T) {
+	ws := make([]int32, MulModWorkspaceSize)
+	for i := 0; i < 4; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		if i == 0 {
+			for j := range f {
+				f[j], g[j] = 9828, 9828
+			}
+		}
+		c := new([768]int32)
+		d := new([768]int32)
+		textbookMulRing(c, f, g)
+		MulMod(d, f, g)
+		if *c != *d {
+			t.Fatalf("c != d for i=%d", i)
+		}
+		MulModWithWorkspace(f, f, g, ws)
+		if *c != *f {
+			t.Fatalf("c != f for i=%d", i)
+		}
+	}
+}
+
+func TestMulChain(t *testing.T) {
+	r := ntruModulus()
+	h := new([768]int32)
+	if err := MulChain(h, nil, r); err != nil {
+		t.Fatal(err)
+	}
+	if one := (&[768]int32{1}); *h != *one {
+		t.Fatal("empty chain is not the identity")
+	}
+
+	factors := make([]*[768]int32, 5)
+	for i := range factors {
+		factors[i] = randPoly(new([768]int32))
+	}
+	if err := MulChain(h, factors[:1], r); err != nil {
+		t.Fatal(err)
+	}
+	if *h != *factors[0] {
+		t.Fatal("chain of one is not a copy")
+	}
+
+	for n := 2; n <= len(factors); n++ {
+		c := new([768]int32)
+		*c = *factors[0]
+		for i := 1; i < n; i++ {
+			textbookMulRing(c, c, factors[i])
+		}
+		if err := MulChain(h, factors[:n], r); err != nil {
+			t.Fatal(err)
+		}
+		if *c != *h {
+			t.Fatalf("c != h for n=%d", n)
+		}
+	}
+}
+
+// textbookMulCyclic multiplies f and g modulo x^768 - 1.
+func textbookMulCyclic(h *[768]int32, f, g *[768]int32) {
+	*h = [768]int32{}
+	for i := 0; i < 768; i++ {
+		for j := 0; j < 768; j++ {
+			k := (i + j) % 768
+			h[k] = (h[k] + f[i]*g[j]) % 9829
+		}
+	}
+}
+
+func TestFoldCyclic(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		c := new([768]int32)
+		d := new([768]int32)
+		h := new([1536]int32)
+		textbookMulCyclic(c, f, g)
+		Mul(h, f, g)
+		FoldCyclic(d, h)
+		if *c != *d {
+			t.Fatalf("c != d for i=%d", i)
+		}
+	}
+}
+
+func TestInvertModRoundTrip(t *testing.T) {
+	one := new([768]int32)
+	one[0] = 1
+	x := new([768]int32)
+	x[1] = 1
+	for _, f := range []*[768]int32{one, x, randPoly(new([768]int32)), randPoly(new([768]int32))} {
+		finv := new([768]int32)
+		if err := InvertMod(finv, f); err != nil {
+			t.Fatal(err)
+		}
+		h := new([768]int32)
+		MulMod(h, f, finv)
+		if *h != *one {
+			t.Fatal("f*finv != 1")
+		}
+	}
+	finv := new([768]int32)
+	finv[0] = 1234
+	if err := InvertMod(finv, new([768]int32)); err == nil {
+		t.Fatal("inverted 0")
+	}
+	if finv[0] != 1234 {
+		t.Fatal("finv modified on error")
+	}
+}
+
+func TestCyclicMulRandom(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		c := new([768]int32)
+		textbookMulCyclic(c, f, g)
+		CyclicMul(f, f, g)
+		if *c != *f {
+			t.Fatalf("c != f for i=%d", i)
+		}
+	}
+
+	// x^767 * x = 1
+	f := &[768]int32{767: 1}
+	g := &[768]int32{1: 1}
+	h := new([768]int32)
+	CyclicMul(h, f, g)
+	if *h != [768]int32{1} {
+		t.Fatal("x^767 * x != 1")
+	}
+}
+
+// textbookMulNegCyclic multiplies f and g modulo x^768 + 1.
+func textbookMulNegCyclic(h *[768]int32, f, g *[768]int32) {
+	*h = [768]int32{}
+	for i := 0; i < 768; i++ {
+		for j := 0; j < 768; j++ {
+			k, c := i+j, f[i]*g[j]%9829
+			if k >= 768 {
+				k, c = k-768, 9829-c
+			}
+			h[k] = (h[k] + c) % 9829
+		}
+	}
+}
+
+func TestNegCyclicMul(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	c := new([768]int32)
+	textbookMulNegCyclic(c, f, g)
+	NegCyclicMul(f, f, g)
+	if *c != *f {
+		t.Fatal("c != f")
+	}
+
+	// x^767 * x = -1
+	f = &[768]int32{767: 1}
+	g = &[768]int32{1: 1}
+	NegCyclicMul(c, f, g)
+	if *c != [768]int32{9828} {
+		t.Fatal("x^767 * x != -1")
+	}
+}
+
+func TestNegCyclicMulCommutative(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	c := new([768]int32)
+	d := new([768]int32)
+	NegCyclicMul(c, f, g)
+	NegCyclicMul(d, g, f)
+	if *c != *d {
+		t.Fatal("f*g != g*f")
+	}
+}
+
+func TestNegCyclicMulDistributive(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	k := randPoly(new([768]int32))
+	s := new([768]int32)
+	thinPoly(s[:]).Add(g[:], k[:])
+	c := new([768]int32)
+	d := new([768]int32)
+	e := new([768]int32)
+	NegCyclicMul(c, f, s)
+	NegCyclicMul(d, f, g)
+	NegCyclicMul(e, f, k)
+	thinPoly(d[:]).Add(d[:], e[:])
+	if *c != *d {
+		t.Fatal("f*(g+k) != f*g + f*k")
+	}
+}
+
+func TestMulDistributive(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		h := randPoly(new([768]int32))
+		s := new([768]int32)
+		Add(s, f, g)
+		c := new([768]int32)
+		MulMod(c, s, h)
+		d := new([768]int32)
+		e := new([768]int32)
+		MulMod(d, f, h)
+		MulMod(e, g, h)
+		Add(d, d, e)
+		if *c != *d {
+			t.Fatal("(f+g)*h != f*h + g*h")
+		}
+	}
+}
+
+func TestMulAssociative(t *testing.T) {
+	for i := 0; i < 8; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		h := randPoly(new([768]int32))
+		c := new([768]int32)
+		MulMod(c, f, g)
+		MulMod(c, c, h)
+		d := new([768]int32)
+		MulMod(d, g, h)
+		MulMod(d, f, d)
+		if *c != *d {
+			t.Fatal("(f*g)*h != f*(g*h)")
+		}
+	}
+}
+
+func TestAlgebraicHash(t *testing.T) {
+	a := randPoly(new([768]int32))
+
+	// compare with Horner's rule for a polynomial of low degree
+	f := new([768]int32)
+	for i := 0; i < 40; i++ {
+		f[i] = int32(rand.Intn(9829))
+	}
+	y := new([768]int32)
+	for i := 39; i >= 0; i-- {
+		textbookMulRing(y, y, a)
+		y[0] = Freeze(y[0] + f[i])
+	}
+	if x := AlgebraicHash(f, a); x != Sum(y) {
+		t.Fatalf("x=%d != Sum(y)=%d", x, Sum(y))
+	}
+
+	// linearity in f
+	f = randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	s := new([768]int32)
+	for i := range s {
+		s[i] = Freeze(f[i] + g[i])
+	}
+	x := AlgebraicHash(s, a)
+	z := Freeze(AlgebraicHash(f, a) + AlgebraicHash(g, a))
+	if x != z {
+		t.Fatalf("x=%d != z=%d", x, z)
+	}
+}
+
+func newNTRURing(t *testing.T) *Ring {
+	ring, err := NewRing(ntruModulus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+// randElement returns a random element of ring.
+func randElement(ring *Ring) []int32 {
+	f := ring.New()
+	for i := range f {
+		f[i] = rand.Int31n(ring.Q)
+	}
+	return f
+}
+
+// textbookMulParams multiplies f and g in ring, with a schoolbook product
+// reduced by long division.
+func textbookMulParams(ring *Ring, h, f, g []int32) {
+	n, q := ring.N, int64(ring.Q)
+	c := make([]int64, 2*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			c[i+j] = (c[i+j] + int64(f[i])*int64(g[j])) % q
+		}
+	}
+	inv := int64(ring.z.inverse(ring.Mod[n]))
+	for k := 2*n - 1; k >= n; k-- {
+		x := c[k] * inv % q
+		for i := 0; i <= n; i++ {
+			c[k-n+i] = ((c[k-n+i]-x*int64(ring.Mod[i]))%q + q) % q
+		}
+	}
+	for i := 0; i < n; i++ {
+		h[i] = int32(c[i])
+	}
+}
+
+var ntruPrimeRings = map[string]*Ring{
+	"653":  Ring653,
+	"761":  Ring761,
+	"768":  Ring768,
+	"857":  Ring857,
+	"953":  Ring953,
+	"1013": Ring1013,
+	"1277": Ring1277,
+}
+
+func TestRingString(t *testing.T) {
+	s := newNTRURing(t).String()
+	if s != "Z_9829[x]/(x^768 + 9828*x + 9828)" {
+		t.Fatalf("s=%q", s)
+	}
+	if s = Ring761.String(); s != "Z_4591[x]/(x^761 + 4590*x + 4590)" {
+		t.Fatalf("s=%q", s)
+	}
+}
+
+func TestNewRingParams(t *testing.T) {
+	r := []int32{-1, -1, 0, 1}
+	for _, q := range []int32{-7, 0, 1, 2, 9, 9827, 1 << 15, 32771} {
+		if _, err := NewRingParams(q, r); err == nil {
+			t.Fatalf("NewRingParams accepted q=%d", q)
+		}
+	}
+	if _, err := NewRingParams(7, []int32{1}); err == nil {
+		t.Fatal("NewRingParams accepted a modulus of degree 0")
+	}
+	if _, err := NewRingParams(7, []int32{-1, -1, 0, 7}); err == nil {
+		t.Fatal("NewRingParams accepted a modulus of degree < 3")
+	}
+	ring, err := NewRingParams(7, []int32{-1, -1, 0, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ring.N != 3 || ring.Q != 7 {
+		t.Fatalf("N=%d Q=%d", ring.N, ring.Q)
+	}
+}
+
+func TestRingArithmetic(t *testing.T) {
+	ring := newNTRURing(t)
+	f := randElement(ring)
+	g := randElement(ring)
+	h := ring.New()
+
+	ring.Add(h, f, g)
+	ring.Sub(h, h, g)
+	if !ring.Equal(h, f) {
+		t.Fatal("f + g - g != f")
+	}
+	ring.Neg(h, f)
+	ring.Add(h, h, f)
+	if !ring.IsZero(h) {
+		t.Fatal("f + -f != 0")
+	}
+
+	c := new([768]int32)
+	textbookMulRing(c, (*[768]int32)(f), (*[768]int32)(g))
+	ring.Mul(h, f, g)
+	if !ring.Equal(h, c[:]) {
+		t.Fatal("Mul does not match textbook multiplication")
+	}
+
+	ring.Pow(h, f, 0)
+	if !ring.IsOne(h) {
+		t.Fatal("f^0 != 1")
+	}
+	ring.Pow(h, f, 5)
+	c2 := new([768]int32)
+	copy(c2[:], f)
+	for i := 1; i < 5; i++ {
+		textbookMulRing(c2, c2, (*[768]int32)(f))
+	}
+	if !ring.Equal(h, c2[:]) {
+		t.Fatal("Pow(f, 5) != f*f*f*f*f")
+	}
+}
+
+func TestRingParamsMul(t *testing.T) {
+	small, err := NewRingParams(7, []int32{-1, -1, 0, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rings := map[string]*Ring{"small": small}
+	for name, ring := range ntruPrimeRings {
+		rings[name] = ring
+	}
+	for name, ring := range rings {
+		f := randElement(ring)
+		g := randElement(ring)
+		c := ring.New()
+		h := ring.New()
+		textbookMulParams(ring, c, f, g)
+		ring.Mul(h, f, g)
+		if !ring.Equal(c, h) {
+			t.Fatalf("%s: Mul does not match textbook multiplication", name)
+		}
+		for i := range f {
+			f[i] = ring.Q - 1
+		}
+		textbookMulParams(ring, c, f, f)
+		ring.Mul(h, f, f)
+		if !ring.Equal(c, h) {
+			t.Fatalf("%s: Mul does not match textbook multiplication for -1", name)
+		}
+	}
+}
+
+func TestRingFreeze(t *testing.T) {
+	ring := Ring761
+	f := []int32{-1, 4591, -1 << 31, 1<<31 - 1, 4590}
+	ring.Freeze(f)
+	want := []int32{4590, 0, int32((-(1 << 31)%4591 + 4591) % 4591), (1<<31 - 1) % 4591, 4590}
+	for i := range f {
+		if f[i] != want[i] {
+			t.Fatalf("f[%d]=%d != %d", i, f[i], want[i])
+		}
+	}
+}
+
+func TestRingInv(t *testing.T) {
+	for _, ring := range []*Ring{newNTRURing(t), Ring761} {
+		for i := 0; i < 4; i++ {
+			f := randElement(ring)
+			h := ring.New()
+			if err := ring.Inv(h, f); err != nil {
+				t.Fatal(err)
+			}
+			ring.Mul(h, h, f)
+			if !ring.IsOne(h) {
+				t.Fatalf("%v: f * f^-1 != 1 for i=%d", ring, i)
+			}
+		}
+		if err := ring.Inv(ring.New(), ring.New()); err == nil {
+			t.Fatalf("%v: Inv accepted zero", ring)
+		}
+	}
+
+	// x - 1 divides x^768 - 1
+	r := new([769]int32)
+	r[0], r[768] = -1, 1
+	cyclic, err := NewRing(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := cyclic.New()
+	f[0], f[1] = 9828, 1
+	if err := cyclic.Inv(cyclic.New(), f); err == nil {
+		t.Fatal("Inv accepted a zero divisor")
+	}
+}
+
+func TestRingSampleUniform(t *testing.T) {
+	for _, ring := range []*Ring{newNTRURing(t), Ring761} {
+		f, err := ring.SampleUniform(rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(f) != ring.N {
+			t.Fatalf("len(f)=%d", len(f))
+		}
+		for i, c := range f {
+			if c < 0 || c >= ring.Q {
+				t.Fatalf("f[%d]=%d", i, c)
+			}
+		}
+		if _, err := ring.SampleUniform(strings.NewReader("short")); err == nil {
+			t.Fatal("SampleUniform did not fail on a short reader")
+		}
+		// Zero bytes are never rejected, so exactly 2N are consumed.
+		r := bytes.NewReader(make([]byte, 2*ring.N+3))
+		if _, err := ring.SampleUniform(r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Len() != 3 {
+			t.Fatalf("SampleUniform left %d bytes, want 3", r.Len())
+		}
+	}
+}
+
+func TestRingSampleSmallWeight(t *testing.T) {
+	ring := newNTRURing(t)
+	src := rand.New(rand.NewSource(1))
+	for _, w := range []int{0, 1, 286, 767, 768} {
+		f, err := ring.SampleSmallWeight(w, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, neg := 0, 0
+		for i, c := range f {
+			switch c {
+			case 0:
+			case 9828:
+				neg++
+				fallthrough
+			case 1:
+				n++
+			default:
+				t.Fatalf("f[%d]=%d for w=%d", i, c, w)
+			}
+		}
+		if n != w {
+			t.Fatalf("weight %d != %d", n, w)
+		}
+		if w == 286 && (neg == 0 || neg == w) {
+			t.Fatalf("neg=%d for w=%d", neg, w)
+		}
+	}
+	for _, w := range []int{-1, 769} {
+		if _, err := ring.SampleSmallWeight(w, src); err == nil {
+			t.Fatalf("SampleSmallWeight accepted w=%d", w)
+		}
+	}
+	if _, err := ring.SampleSmallWeight(286, strings.NewReader("short")); err == nil {
+		t.Fatal("SampleSmallWeight did not fail on a short reader")
+	}
+	// 36 bytes of signs and 767 accepted draws of two bytes.
+	r := bytes.NewReader(make([]byte, 36+2*767+3))
+	if _, err := ring.SampleSmallWeight(286, r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 {
+		t.Fatalf("SampleSmallWeight left %d bytes, want 3", r.Len())
+	}
+}
+
+func TestRingEncode(t *testing.T) {
+	ring := newNTRURing(t)
+	f := randElement(ring)
+	buf := ring.Encode(f)
+	want := make([]byte, packedSize)
+	pack(want, (*[768]int32)(f))
+	if string(buf) != string(want) {
+		t.Fatal("Encode does not match pack")
+	}
+
+	for name, ring := range ntruPrimeRings {
+		f := randElement(ring)
+		f[ring.N-1] = ring.Q - 1
+		buf := ring.Encode(f)
+		if len(buf) != ring.EncodedLen() {
+			t.Fatalf("%s: len(buf)=%d", name, len(buf))
+		}
+		g, err := ring.Decode(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ring.Equal(f, g) {
+			t.Fatalf("%s: f != g", name)
+		}
+		if _, err := ring.Decode(buf[1:]); err == nil {
+			t.Fatalf("%s: Decode accepted a short buffer", name)
+		}
+	}
+
+	// 4591 = 0x11ef; 0x1fff does not fit in Z_4591
+	buf = Ring761.Encode(randElement(Ring761))
+	buf[0], buf[1] = 0xff, buf[1]|0x1f
+	if _, err := Ring761.Decode(buf); err == nil {
+		t.Fatal("Decode accepted an out of range coefficient")
+	}
+}
+
+func TestNewRandTernaryDistribution(t *testing.T) {
+	trials := 10000
+	if testing.Short() {
+		trials = 1000
+	}
+	const ones, negones = 12, 8
+	var pos, neg [768]int
+	f := new([768]int32)
+	src := rand.New(rand.NewSource(1))
+	for i := 0; i < trials; i++ {
+		if err := NewRandTernary(f, ones, negones, src); err != nil {
+			t.Fatal(err)
+		}
+		if HammingWeight(f) != ones+negones || NegWeight(f) != negones || !IsSmall(f) {
+			t.Fatalf("wrong weights: %v", f)
+		}
+		for j, x := range f {
+			switch x {
+			case 1:
+				pos[j]++
+			case 9828:
+				neg[j]++
+			}
+		}
+	}
+
+	// each count is binomial; allow six standard deviations
+	for _, c := range []struct {
+		count *[768]int
+		w     int
+	}{{&pos, ones}, {&neg, negones}} {
+		p := float64(c.w) / 768
+		mean := float64(trials) * p
+		dev := 6 * math.Sqrt(mean*(1-p))
+		for j, n := range c.count {
+			if math.Abs(float64(n)-mean) > dev {
+				t.Fatalf("position %d taken %d times, expected %.0f", j, n, mean)
+			}
+		}
+	}
+
+	if err := NewRandTernary(f, 500, 269, src); err == nil {
+		t.Fatal("NewRandTernary accepted 769 nonzero coefficients")
+	}
+	f[0] = 5
+	if err := NewRandTernary(f, 1, 1, strings.NewReader("short")); err == nil {
+		t.Fatal("NewRandTernary did not fail on a short reader")
+	}
+	if f[0] != 5 {
+		t.Fatal("f modified on error")
+	}
+
+	// zero bytes are never rejected, so exactly 767 draws are made
+	r := bytes.NewReader(make([]byte, 2*767+3))
+	if err := NewRandTernary(f, ones, negones, r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 {
+		t.Fatalf("NewRandTernary left %d bytes, want 3", r.Len())
+	}
+}
+
+func TestNewRandPolyUniform(t *testing.T) {
+	polys := 131 // about 100000 coefficients
+	if testing.Short() {
+		polys = 14
+	}
+	src := rand.New(rand.NewSource(1))
+	f := new([768]int32)
+	for _, q := range []int32{2, 17, 9829, 65536} {
+		count := make([]int, q)
+		for i := 0; i < polys; i++ {
+			if err := NewRandPoly(f, q, src); err != nil {
+				t.Fatal(err)
+			}
+			for _, x := range f {
+				if x < 0 || x >= q {
+					t.Fatalf("q=%d: coefficient %d out of range", q, x)
+				}
+				count[x]++
+			}
+		}
+
+		// chi-squared with q-1 degrees of freedom; allow six standard
+		// deviations above its mean
+		mean := float64(polys*768) / float64(q)
+		var chi2 float64
+		for _, n := range count {
+			d := float64(n) - mean
+			chi2 += d * d / mean
+		}
+		df := float64(q - 1)
+		if chi2 > df+6*math.Sqrt(2*df) {
+			t.Fatalf("q=%d: chi-squared %.1f for %v degrees of freedom", q, chi2, df)
+		}
+	}
+
+	for _, q := range []int32{-1, 0, 1, 65537} {
+		if err := NewRandPoly(f, q, src); err == nil {
+			t.Fatalf("NewRandPoly accepted q=%d", q)
+		}
+	}
+	f[0] = 5
+	if err := NewRandPoly(f, 9829, strings.NewReader("short")); err == nil {
+		t.Fatal("NewRandPoly did not fail on a short reader")
+	}
+	if f[0] != 5 {
+		t.Fatal("f modified on error")
+	}
+
+	// each draw consumes exactly two bytes
+	r := bytes.NewReader(make([]byte, 2*768+3))
+	if err := NewRandPoly(f, 9829, r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Len() != 3 {
+		t.Fatalf("NewRandPoly left %d bytes, want 3", r.Len())
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Interpolation parameters for Toom3, over the points 0, +1, -1, +2 and
+// infinity.
+var toom3Param = [][]int32{
+	{4914, 1, 3276, 1638, 2},
+	{9828, 4915, 4915, 0, 9828},
+	{4915, 4914, 1638, 8191, 9827},
+}
+
+// Toom3 decomposes a 384n x 384n multiplication into three instances of 128n x
+// 128n.
+func (r thinPoly) Toom3(f, g *[384]int32) thinPoly {
+	ws := make(thinPoly, toomEvalWorkspace)
+	var e = [][]int32{
+		make(thinPoly, 256).Karatsuba1(f[0:128], g[0:128], ws),
+		make(thinPoly, 256).toomEval(+1, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(-1, f[:], g[:], ws),
+		make(thinPoly, 256).toomEval(+2, f[:], g[:], ws),
+		make(thinPoly, 256).Karatsuba1(f[256:384], g[256:384], ws),
+	}
+	var c = [][]int32{
+		e[0],
+		make(thinPoly, 256).toomInterpolate(e, toom3Param[0], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom3Param[1], ws),
+		make(thinPoly, 256).toomInterpolate(e, toom3Param[2], ws),
+		e[4],
+	}
+
+	copy(r[:128], c[0])
+	r[128:].Add(c[0][128:], c[1][:128])
+	r[256:].Add(c[1][128:], c[2][:128])
+	r[384:].Add(c[2][128:], c[3][:128])
+	r[512:].Add(c[3][128:], c[4][:128])
+	copy(r[640:], c[4][128:])
+
+	return r
+}
+
+// MulSparse is like Mul for inputs whose coefficients of degree deg and
+// above are all zero. Depending on deg, it uses Karatsuba1 directly
+// (deg <= 128), Toom3 (deg <= 384), Toom5 (deg <= 640), or the full Toom6,
+// avoiding the evaluations that would only involve zero blocks. The result
+// is wrong if f or g has a nonzero coefficient of degree deg or above.
+func MulSparse(h *[1536]int32, f, g *[768]int32, deg int) {
+	z := thinPoly(h[:])
+	switch {
+	case deg <= 128:
+		z[:256].Karatsuba1(f[:128], g[:128], nil)
+		z[256:].Zero()
+	case deg <= 384:
+		z[:767].Toom3((*[384]int32)(f[:384]), (*[384]int32)(g[:384]))
+		z[767:].Zero()
+	case deg <= 640:
+		Mul640((*[1279]int32)(h[:1279]), (*[640]int32)(f[:640]), (*[640]int32)(g[:640]))
+		z[1279:].Zero()
+	default:
+		z.Toom6(f, g, nil)
+	}
+}
+
+// TernaryMul is like Mul for a ternary polynomial, one whose coefficients are
+// all 0, 1 or 9828, that is, one for which IsSmall holds. Each nonzero
+// coefficient of ternary adds or subtracts a shifted copy of g, so the cost
+// is proportional to the weight of ternary: about a third of that of Mul at
+// weight 286, and less for sparser inputs. The sums, at most 768*9828 in
+// absolute value, are reduced once at the end.
+//
+// Unlike Mul, TernaryMul branches on the coefficients of ternary, so its
+// running time reveals their positions and signs. It must not be used when
+// ternary is secret. The result is wrong if ternary is not ternary.
+func TernaryMul(h *[1536]int32, ternary, g *[768]int32) {
+	z := thinPoly(h[:]).Zero()
+	for i, x := range ternary {
+		switch x {
+		case 1:
+			z[i : i+768].Inc(g[:])
+		case 9828:
+			row := z[i : i+768]
+			for j, y := range g {
+				row[j] -= y
+			}
+		}
+	}
+	z.Freeze()
+}
+
+// ShiftMul sets h to f*x^k, the product of f by a monomial, which is f with
+// its coefficients moved up by k places. k must be in [0, 767], the degrees
+// of monomials that Mul accepts, and the coefficients of f in [0, 9828], for
+// h to equal the result of Mul.
+func ShiftMul(h *[1536]int32, f *[768]int32, k int) {
+	if k < 0 || k > 767 {
+		panic("karatsuba768: shift out of range")
+	}
+	z := thinPoly(h[:]).Zero()
+	copy(z[k:], f[:])
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMulSparse(t *testing.T) {
+	for _, deg := range []int{0, 1, 128, 129, 384, 385, 640, 641, 768} {
+		a := new([768]int32)
+		b := new([768]int32)
+		for j := 0; j < deg; j++ {
+			a[j] = int32(rand.Intn(9829))
+			b[j] = int32(rand.Intn(9829))
+		}
+		c := new([1536]int32)
+		d := new([1536]int32)
+		for j := range d {
+			d[j] = -1
+		}
+		Mul(c, a, b)
+		MulSparse(d, a, b, deg)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d for deg=%d: %v", deg, err)
+		}
+	}
+}
+
+func BenchmarkMulSparse128(b *testing.B) { benchmarkMulSparse(b, 128) }
+func BenchmarkMulSparse384(b *testing.B) { benchmarkMulSparse(b, 384) }
+func BenchmarkMulSparse640(b *testing.B) { benchmarkMulSparse(b, 640) }
+
+func benchmarkMulSparse(b *testing.B, deg int) {
+	f := new([768]int32)
+	g := new([768]int32)
+	for j := 0; j < deg; j++ {
+		f[j] = int32(rand.Intn(9829))
+		g[j] = int32(rand.Intn(9829))
+	}
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		MulSparse(h, f, g, deg)
+	}
+}
+
+// randTernary returns a ternary polynomial of the given weight, with
+// coefficients 1 and 9828 in random positions.
+func randTernary(weight int) *[768]int32 {
+	f := new([768]int32)
+	for _, i := range rand.Perm(768)[:weight] {
+		f[i] = 1 + 9827*int32(rand.Intn(2))
+	}
+	return f
+}
+
+func TestTernaryMul(t *testing.T) {
+	for _, w := range []int{0, 1, 286, 768} {
+		a := randTernary(w)
+		if !IsSmall(a) {
+			t.Fatalf("IsSmall false for a ternary polynomial of weight %d", w)
+		}
+		b := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		for j := range d {
+			d[j] = -1
+		}
+		Mul(c, a, b)
+		TernaryMul(d, a, b)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d for weight %d: %v", w, err)
+		}
+	}
+
+	// all coefficients 9828, the largest sums
+	a := new([768]int32)
+	b := new([768]int32)
+	for j := range a {
+		a[j], b[j] = 9828, 9828
+	}
+	c := new([1536]int32)
+	d := new([1536]int32)
+	Mul(c, a, b)
+	TernaryMul(d, a, b)
+	if err := cmpPoly(t, c, d); err != nil {
+		t.Fatal(err)
+	}
+
+	a[5] = 2
+	if IsSmall(a) {
+		t.Fatal("IsSmall true for a coefficient of 2")
+	}
+}
+
+func TestShiftMulCorrectness(t *testing.T) {
+	f := randPoly(new([768]int32))
+	for _, k := range []int{0, 1, 127, 128, 500, 767} {
+		e := new([768]int32)
+		e[k] = 1
+		c := new([1536]int32)
+		d := new([1536]int32)
+		for j := range d {
+			d[j] = -1
+		}
+		Mul(c, f, e)
+		ShiftMul(d, f, k)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d for k=%d: %v", k, err)
+		}
+	}
+
+	for _, k := range []int{-1, 768} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("ShiftMul accepted k=%d", k)
+				}
+			}()
+			ShiftMul(new([1536]int32), f, k)
+		}()
+	}
+}
+
+func BenchmarkTernaryMul(b *testing.B) {
+	f := randTernary(286)
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		TernaryMul(h, f, g)
+	}
+}
+
+func BenchmarkMulTernary(b *testing.B) {
+	f := randTernary(286)
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		Mul(h, f, g)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestSquare(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		f := randPoly(new([768]int32))
+		if i == 0 {
+			for j := range f {
+				f[j] = 9828
+			}
+		}
+		c := new([1536]int32)
+		d := new([1536]int32)
+		textbookMul(c, f, f)
+		Square(d, f)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d for i=%d: %v", i, err)
+		}
+	}
+}
+
+func BenchmarkSquare(b *testing.B) {
+	f := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		Square(h, f)
+	}
+}
+
+func BenchmarkMulSelf(b *testing.B) {
+	f := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		Mul(h, f, f)
+	}
+}
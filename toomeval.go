@@ -0,0 +1,112 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// ToomEvalCoeffs holds, for each point p at which Toom6 evaluates its
+// operands besides zero and infinity, the weights 1, p, p^2, ..., p^5 of
+// their six blocks; Toom5 and smaller variants use a prefix of each row. It is
+// a copy of the table used by Mul, which modifying it does not affect, and is
+// meant as a model for the tables of other Toom decompositions.
+//
+// The rows are those of a Vandermonde matrix: row p must be the powers of p,
+// reduced modulo 9829 when they are used. Interpolation needs that matrix,
+// extended with the rows (1, 0, ..., 0) and (0, ..., 0, 1) for zero and
+// infinity, to be invertible over GF(9829), which holds if and only if the
+// points are distinct modulo 9829. A new table can be checked by comparing
+// each row against the powers of its point and the points pairwise, and the
+// interpolation parameters, such as those of Toom6, by multiplying them by the
+// matrix and checking for the identity.
+var ToomEvalCoeffs = func() map[int][]int32 {
+	m := make(map[int][]int32, len(toomEvalCoeffs))
+	for p, c := range toomEvalCoeffs {
+		m[p] = append([]int32(nil), c...)
+	}
+	return m
+}()
+
+// ToomEvalPoint evaluates f, split in blocks of blockSize coefficients, at the
+// point p of ToomEvalCoeffs: it returns the sum of the blocks weighted by the
+// row of p, with the coefficients reduced. blockSize must divide 768 into at
+// most six blocks, that is, be one of 128, 192, 256, 384 or 768. toomEval does
+// this for blocks of 128 coefficients, for which ToomEvalPoint returns the
+// same evaluations.
+func ToomEvalPoint(p int, f *[768]int32, blockSize int) []int32 {
+	if _, ok := toomEvalCoeffs[p]; !ok {
+		panic("karatsuba768: not a Toom evaluation point")
+	}
+	if blockSize < 128 || 768%blockSize != 0 {
+		panic("karatsuba768: invalid block size")
+	}
+	return toomEvalBlocks(p, f[:], blockSize, nil)[:blockSize]
+}
+
+// ToomEvaluated is a polynomial evaluated at the eleven points used by Toom6:
+// its lowest and highest blocks of 128 coefficients, for the points zero and
+// infinity, and its evaluations at toom6Points.
+type ToomEvaluated struct {
+	e [11][128]int32
+}
+
+// ToomEvalPoly evaluates f at the Toom6 points. The result can be passed to
+// MulFromEval to multiply f by several polynomials, such as a public key by
+// several messages, without evaluating f again.
+func ToomEvalPoly(f *[768]int32) *ToomEvaluated {
+	fe := new(ToomEvaluated)
+	ws := make(thinPoly, 256)
+	copy(fe.e[0][:], f[0:128])
+	for i, p := range toom6Points {
+		copy(fe.e[i+1][:], toomEvalOne(p, f[:], ws))
+	}
+	copy(fe.e[10][:], f[640:768])
+	return fe
+}
+
+// MulFromEval sets h to the product f*g, where fe = ToomEvalPoly(f). Only g
+// is evaluated; the eleven 128n x 128n products and the interpolation are
+// the same as in Mul. As these dominate, the gain is small: about 4%, or
+// 149us against 155us for Mul on amd64 with AVX2.
+func MulFromEval(h *[1536]int32, fe *ToomEvaluated, g *[768]int32) {
+	ws := mulPool.Get().(*[MulWorkspaceSize]int32)
+	fe.mul(h, g, ws[:])
+	mulPool.Put(ws)
+}
+
+// mul is MulFromEval, taking its intermediate values from ws, which must have
+// room for MulWorkspaceSize coefficients. They are laid out as in Toom6, the
+// scratch space holding the evaluation of g followed by the workspace of
+// Karatsuba1.
+func (fe *ToomEvaluated) mul(h *[1536]int32, g *[768]int32, ws thinPoly) {
+	var e [11][]int32
+	for i := range e {
+		e[i] = ws[i*256 : (i+1)*256]
+	}
+	w := ws[11*256:]
+
+	thinPoly(e[0]).Karatsuba1(fe.e[0][:], g[0:128], w)
+	for i, p := range toom6Points {
+		gp := toomEvalOne(p, g[:], w[:256])
+		thinPoly(e[i+1]).Karatsuba1(fe.e[i+1][:], gp, w[256:])
+	}
+	thinPoly(e[10]).Karatsuba1(fe.e[10][:], g[640:768], w)
+	thinPoly(h[:]).toom6Combine(e[:], w)
+}
+
+// BatchMul sets hs[i] to f*gs[i] for every i, evaluating f at the Toom6
+// points once rather than once per product, as with ToomEvalPoly and
+// MulFromEval. hs and gs must have the same length. The saving is the
+// evaluation of f only: each product still takes eleven 128n x 128n
+// multiplications and an interpolation, so 16 products take about 4% less
+// time than 16 calls to Mul (2.40ms against 2.50ms on amd64 with AVX2).
+func BatchMul(hs [][1536]int32, f *[768]int32, gs []*[768]int32) {
+	if len(hs) != len(gs) {
+		panic("karatsuba768: BatchMul with mismatched lengths")
+	}
+	fe := ToomEvalPoly(f)
+	ws := mulPool.Get().(*[MulWorkspaceSize]int32)
+	for i, g := range gs {
+		fe.mul(&hs[i], g, ws[:])
+	}
+	mulPool.Put(ws)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import "testing"
+
+func TestMulFromEval(t *testing.T) {
+	f := randPoly(new([768]int32))
+	fe := ToomEvalPoly(f)
+	for i := 0; i < 8; i++ {
+		g := randPoly(new([768]int32))
+		c := new([1536]int32)
+		d := new([1536]int32)
+		Mul(c, f, g)
+		MulFromEval(d, fe, g)
+		if err := cmpPoly(t, c, d); err != nil {
+			t.Fatalf("c != d: %v", err)
+		}
+	}
+}
+
+func BenchmarkMulFromEval(b *testing.B) {
+	fe := ToomEvalPoly(randPoly(new([768]int32)))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	for i := 0; i < b.N; i++ {
+		MulFromEval(h, fe, g)
+	}
+}
+
+func TestBatchMul(t *testing.T) {
+	f := randPoly(new([768]int32))
+	gs := make([]*[768]int32, 5)
+	for i := range gs {
+		gs[i] = randPoly(new([768]int32))
+	}
+	hs := make([][1536]int32, len(gs))
+	BatchMul(hs, f, gs)
+	for i, g := range gs {
+		c := new([1536]int32)
+		Mul(c, f, g)
+		if err := cmpPoly(t, c, &hs[i]); err != nil {
+			t.Fatalf("c != hs[%d]: %v", i, err)
+		}
+	}
+	BatchMul(nil, f, nil)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("BatchMul accepted mismatched lengths")
+		}
+	}()
+	BatchMul(hs[1:], f, gs)
+}
+
+func benchmarkBatch(n int) (*[768]int32, []*[768]int32, [][1536]int32) {
+	f := randPoly(new([768]int32))
+	gs := make([]*[768]int32, n)
+	for i := range gs {
+		gs[i] = randPoly(new([768]int32))
+	}
+	return f, gs, make([][1536]int32, n)
+}
+
+func BenchmarkBatchMul(b *testing.B) {
+	f, gs, hs := benchmarkBatch(16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		BatchMul(hs, f, gs)
+	}
+}
+
+func BenchmarkBatchMulSeparate(b *testing.B) {
+	f, gs, hs := benchmarkBatch(16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j, g := range gs {
+			Mul(&hs[j], f, g)
+		}
+	}
+}
+
+// TestToomEvalConsistency checks toomEval at every point of toomEvalCoeffs
+// against f(p)*g(p), with the blocks of f and g weighted by powers of p
+// computed here rather than taken from the table.
+func TestToomEvalConsistency(t *testing.T) {
+	ws := make(thinPoly, toomEvalWorkspace)
+	for i := 0; i < 10; i++ {
+		f := randPoly(new([768]int32))
+		g := randPoly(new([768]int32))
+		for p := range toomEvalCoeffs {
+			fp := make(thinPoly, 128)
+			gp := make(thinPoly, 128)
+			w := int32(1)
+			for k := 0; k < 6; k++ {
+				for j := 0; j < 128; j++ {
+					fp[j] = Freeze(fp[j] + w*f[128*k+j])
+					gp[j] = Freeze(gp[j] + w*g[128*k+j])
+				}
+				w = Freeze(w * int32(p))
+			}
+			want := make(thinPoly, 256).Karatsuba1(fp, gp, nil)
+			got := make(thinPoly, 256).toomEval(p, f[:], g[:], ws)
+			for j := range want {
+				if got[j] != want[j] {
+					t.Fatalf("p=%d: got[%d]=%d != %d", p, j, got[j], want[j])
+				}
+			}
+		}
+	}
+}
+
+func TestToomEvalCoeffsVandermonde(t *testing.T) {
+	seen := make(map[int32]int)
+	for p, c := range ToomEvalCoeffs {
+		w := int32(1)
+		for i, v := range c {
+			if v != w {
+				t.Fatalf("ToomEvalCoeffs[%d][%d]=%d, want %d", p, i, v, w)
+			}
+			w *= int32(p)
+		}
+		x := Freeze(int32(p))
+		if q, ok := seen[x]; ok || x == 0 {
+			t.Fatalf("points %d and %d coincide modulo 9829", p, q)
+		}
+		seen[x] = p
+	}
+
+	// ToomEvalCoeffs is a copy
+	ToomEvalCoeffs[2][1] = 3
+	defer func() { ToomEvalCoeffs[2][1] = 2 }()
+	if toomEvalCoeffs[2][1] != 2 {
+		t.Fatal("modifying ToomEvalCoeffs changed toomEvalCoeffs")
+	}
+}
+
+func TestToomEvalPoint(t *testing.T) {
+	f := randPoly(new([768]int32))
+	for _, n := range []int{128, 192, 256, 384, 768} {
+		for p := range toomEvalCoeffs {
+			want := make([]int32, n)
+			w := int32(1)
+			for k := 0; k < 768/n; k++ {
+				for j := range want {
+					want[j] = Freeze(want[j] + w*f[n*k+j])
+				}
+				w = Freeze(w * int32(p))
+			}
+			got := ToomEvalPoint(p, f, n)
+			if len(got) != n {
+				t.Fatalf("p=%d, n=%d: %d coefficients", p, n, len(got))
+			}
+			for j := range want {
+				if got[j] != want[j] {
+					t.Fatalf("p=%d, n=%d: got[%d]=%d != %d", p, n, j, got[j], want[j])
+				}
+			}
+		}
+	}
+
+	for _, c := range []struct{ p, n int }{{0, 128}, {6, 128}, {1, 64}, {1, 100}, {1, 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("ToomEvalPoint accepted p=%d, blockSize=%d", c.p, c.n)
+				}
+			}()
+			ToomEvalPoint(c.p, f, c.n)
+		}()
+	}
+}
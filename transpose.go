@@ -0,0 +1,23 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+// Transpose sets out to x^767 * in(1/x), which reverses the order of the
+// coefficients of in. out may alias in.
+func Transpose(out *[768]int32, in *[768]int32) {
+	t := *in
+	for i := range out {
+		out[i] = t[767-i]
+	}
+}
+
+// TransposeMul sets h to Transpose(f)*g, computed with Mul. The coefficient
+// h[767+d] is the correlation sum f[0]*g[d] + f[1]*g[d+1] + ... of f and g at
+// lag d, for d in [-767, 767], and h[1535] is always zero.
+func TransposeMul(h *[1536]int32, f, g *[768]int32) {
+	t := new([768]int32)
+	Transpose(t, f)
+	Mul(h, t, g)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"testing"
+)
+
+func TestTranspose(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := new([768]int32)
+	Transpose(g, f)
+	for i := range g {
+		if g[i] != f[767-i] {
+			t.Fatalf("g[%d]=%d != f[%d]=%d", i, g[i], 767-i, f[767-i])
+		}
+	}
+	Transpose(g, g)
+	if *g != *f {
+		t.Fatal("Transpose is not an involution")
+	}
+}
+
+func TestTransposeMul(t *testing.T) {
+	f := randPoly(new([768]int32))
+	g := randPoly(new([768]int32))
+	h := new([1536]int32)
+	TransposeMul(h, f, g)
+	for d := -767; d <= 767; d++ {
+		var s int32
+		for j := 0; j < 768; j++ {
+			if j+d >= 0 && j+d < 768 {
+				s = (s + f[j]*g[j+d]) % 9829
+			}
+		}
+		if h[767+d] != s {
+			t.Fatalf("h[%d]=%d != %d", 767+d, h[767+d], s)
+		}
+	}
+	if h[1535] != 0 {
+		t.Fatalf("h[1535]=%d", h[1535])
+	}
+}
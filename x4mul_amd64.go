@@ -0,0 +1,31 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+package karatsuba768
+
+// x4MulAVX2Asm sets p[0:8] to a[0:4]*b[0:4], freezing every partial product
+// as x4MulGeneric does. It requires AVX2. Mul is about 15% faster with it.
+//
+//go:noescape
+func x4MulAVX2Asm(p, a, b *int32)
+
+func x4MulAVX2(p, f, g thinPoly) {
+	_, _, _ = p[7], f[3], g[3]
+	x4MulAVX2Asm(&p[0], &f[0], &g[0])
+}
+
+// karatsuba5SquareAVX2Asm sets p[0:16] to the square of a[0:8]. It requires
+// AVX2. Unlike karatsuba5SquareGeneric, it computes the square by schoolbook,
+// so its coefficients are congruent to, but not always equal to, those of the
+// generic code; both lie well inside the domain of Freeze.
+//
+//go:noescape
+func karatsuba5SquareAVX2Asm(p, a *int32)
+
+func karatsuba5SquareAVX2(p, f, _ thinPoly) {
+	_, _ = p[15], f[7]
+	karatsuba5SquareAVX2Asm(&p[0], &f[0])
+}
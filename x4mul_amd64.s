@@ -0,0 +1,195 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// BROADCAST sets the eight lanes of ymm to the constant c.
+#define BROADCAST(c, xmm, ymm) \
+	MOVL         c, AX; \
+	VMOVD        AX, xmm; \
+	VPBROADCASTD xmm, ymm
+
+// FREEZE applies Freeze to the eight lanes of x, given the constants 13, 427,
+// 9829 and 2^21 in c13, c427, c9829 and round. tmp is clobbered.
+#define FREEZE(x, c13, c427, c9829, round, tmp) \
+	VPMULLD c13, x, tmp; \
+	VPSRAD  $17, tmp, tmp; \
+	VPMULLD c9829, tmp, tmp; \
+	VPSUBD  tmp, x, x; \
+	VPMULLD c427, x, tmp; \
+	VPADDD  round, tmp, tmp; \
+	VPSRAD  $22, tmp, tmp; \
+	VPMULLD c9829, tmp, tmp; \
+	VPSUBD  tmp, x, x; \
+	VPSRAD  $31, x, tmp; \
+	VPAND   c9829, tmp, tmp; \
+	VPADDD  tmp, x, x
+
+// func x4MulAVX2Asm(p, a, b *int32)
+//
+// The sixteen products f[i]*g[j] are computed as f*g[0] and f*g[1] in the
+// halves of Y0, and f*g[2] and f*g[3] in those of Y1. Each group of four is
+// then added to p at offset j, which stands in for the shuffles of a
+// triangular sum.
+TEXT ·x4MulAVX2Asm(SB), NOSPLIT, $0-24
+	MOVQ p+0(FP), DI
+	MOVQ a+8(FP), SI
+	MOVQ b+16(FP), DX
+
+	BROADCAST($13, X8, Y8)
+	BROADCAST($427, X9, Y9)
+	BROADCAST($9829, X10, Y10)
+	BROADCAST($2097152, X11, Y11)
+
+	VBROADCASTI128 (SI), Y2
+	VPBROADCASTD   0(DX), X0
+	VPBROADCASTD   4(DX), X3
+	VINSERTI128    $1, X3, Y0, Y0
+	VPBROADCASTD   8(DX), X1
+	VPBROADCASTD   12(DX), X3
+	VINSERTI128    $1, X3, Y1, Y1
+	VPMULLD        Y2, Y0, Y0
+	VPMULLD        Y2, Y1, Y1
+	FREEZE(Y0, Y8, Y9, Y10, Y11, Y4)
+	FREEZE(Y1, Y8, Y9, Y10, Y11, Y4)
+
+	// p[0:8] = 0, then p[j:j+4] += f*g[j]
+	VPXOR       Y3, Y3, Y3
+	VMOVDQU     Y3, (DI)
+	VMOVDQU     X0, (DI)
+	VEXTRACTI128 $1, Y0, X0
+	VMOVDQU     4(DI), X3
+	VPADDD      X0, X3, X3
+	VMOVDQU     X3, 4(DI)
+	VMOVDQU     8(DI), X3
+	VPADDD      X1, X3, X3
+	VMOVDQU     X3, 8(DI)
+	VEXTRACTI128 $1, Y1, X1
+	VMOVDQU     12(DI), X3
+	VPADDD      X1, X3, X3
+	VMOVDQU     X3, 12(DI)
+
+	VZEROUPPER
+	RET
+
+// sqrot<> holds, for each j, the VPERMD indices that rotate eight lanes up
+// by j. sqmask<> holds, for each j, a mask of the lanes at or above j.
+DATA sqrot<>+0x00(SB)/8, $0x0000000100000000
+DATA sqrot<>+0x08(SB)/8, $0x0000000300000002
+DATA sqrot<>+0x10(SB)/8, $0x0000000500000004
+DATA sqrot<>+0x18(SB)/8, $0x0000000700000006
+DATA sqrot<>+0x20(SB)/8, $0x0000000000000007
+DATA sqrot<>+0x28(SB)/8, $0x0000000200000001
+DATA sqrot<>+0x30(SB)/8, $0x0000000400000003
+DATA sqrot<>+0x38(SB)/8, $0x0000000600000005
+DATA sqrot<>+0x40(SB)/8, $0x0000000700000006
+DATA sqrot<>+0x48(SB)/8, $0x0000000100000000
+DATA sqrot<>+0x50(SB)/8, $0x0000000300000002
+DATA sqrot<>+0x58(SB)/8, $0x0000000500000004
+DATA sqrot<>+0x60(SB)/8, $0x0000000600000005
+DATA sqrot<>+0x68(SB)/8, $0x0000000000000007
+DATA sqrot<>+0x70(SB)/8, $0x0000000200000001
+DATA sqrot<>+0x78(SB)/8, $0x0000000400000003
+DATA sqrot<>+0x80(SB)/8, $0x0000000500000004
+DATA sqrot<>+0x88(SB)/8, $0x0000000700000006
+DATA sqrot<>+0x90(SB)/8, $0x0000000100000000
+DATA sqrot<>+0x98(SB)/8, $0x0000000300000002
+DATA sqrot<>+0xa0(SB)/8, $0x0000000400000003
+DATA sqrot<>+0xa8(SB)/8, $0x0000000600000005
+DATA sqrot<>+0xb0(SB)/8, $0x0000000000000007
+DATA sqrot<>+0xb8(SB)/8, $0x0000000200000001
+DATA sqrot<>+0xc0(SB)/8, $0x0000000300000002
+DATA sqrot<>+0xc8(SB)/8, $0x0000000500000004
+DATA sqrot<>+0xd0(SB)/8, $0x0000000700000006
+DATA sqrot<>+0xd8(SB)/8, $0x0000000100000000
+DATA sqrot<>+0xe0(SB)/8, $0x0000000200000001
+DATA sqrot<>+0xe8(SB)/8, $0x0000000400000003
+DATA sqrot<>+0xf0(SB)/8, $0x0000000600000005
+DATA sqrot<>+0xf8(SB)/8, $0x0000000000000007
+GLOBL sqrot<>(SB), RODATA|NOPTR, $256
+
+DATA sqmask<>+0x00(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x08(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x10(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x18(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x20(SB)/8, $0xffffffff00000000
+DATA sqmask<>+0x28(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x30(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x38(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x40(SB)/8, $0x0000000000000000
+DATA sqmask<>+0x48(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x50(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x58(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x60(SB)/8, $0x0000000000000000
+DATA sqmask<>+0x68(SB)/8, $0xffffffff00000000
+DATA sqmask<>+0x70(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x78(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x80(SB)/8, $0x0000000000000000
+DATA sqmask<>+0x88(SB)/8, $0x0000000000000000
+DATA sqmask<>+0x90(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0x98(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0xa0(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xa8(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xb0(SB)/8, $0xffffffff00000000
+DATA sqmask<>+0xb8(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0xc0(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xc8(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xd0(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xd8(SB)/8, $0xffffffffffffffff
+DATA sqmask<>+0xe0(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xe8(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xf0(SB)/8, $0x0000000000000000
+DATA sqmask<>+0xf8(SB)/8, $0xffffffff00000000
+GLOBL sqmask<>(SB), RODATA|NOPTR, $256
+
+// SQROW adds f*f[j] to the sixteen lanes of Y1:Y2 at offset j, where off4 is
+// 4*j and off32 is 32*j. The product is rotated up by j lanes and split
+// between Y1 and Y2 with the mask for j.
+#define SQROW(off4, off32) \
+	VPBROADCASTD off4(SI), Y3; \
+	VPMULLD      Y0, Y3, Y3; \
+	FREEZE(Y3, Y8, Y9, Y10, Y11, Y4); \
+	VMOVDQU      off32(R8), Y6; \
+	VPERMD       Y3, Y6, Y5; \
+	VMOVDQU      off32(R9), Y6; \
+	VPAND        Y5, Y6, Y7; \
+	VPADDD       Y7, Y1, Y1; \
+	VPANDN       Y5, Y6, Y7; \
+	VPADDD       Y7, Y2, Y2
+
+// func karatsuba5SquareAVX2Asm(p, a *int32)
+//
+// p[0:16] is the schoolbook square of a[0:8], kept in Y1 and Y2 as eight rows
+// of frozen products are added in. Each lane of p is a sum of at most eight
+// frozen products, so p stays well inside the domain of Freeze.
+TEXT ·karatsuba5SquareAVX2Asm(SB), NOSPLIT, $0-16
+	MOVQ p+0(FP), DI
+	MOVQ a+8(FP), SI
+	LEAQ sqrot<>(SB), R8
+	LEAQ sqmask<>(SB), R9
+
+	BROADCAST($13, X8, Y8)
+	BROADCAST($427, X9, Y9)
+	BROADCAST($9829, X10, Y10)
+	BROADCAST($2097152, X11, Y11)
+
+	VMOVDQU (SI), Y0
+	VPXOR   Y1, Y1, Y1
+	VPXOR   Y2, Y2, Y2
+	SQROW(0, 0)
+	SQROW(4, 32)
+	SQROW(8, 64)
+	SQROW(12, 96)
+	SQROW(16, 128)
+	SQROW(20, 160)
+	SQROW(24, 192)
+	SQROW(28, 224)
+
+	VMOVDQU Y1, (DI)
+	VMOVDQU Y2, 32(DI)
+
+	VZEROUPPER
+	RET
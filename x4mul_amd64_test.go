@@ -0,0 +1,91 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+//go:build amd64 && !purego
+
+package karatsuba768
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestX4MulAsm(t *testing.T) {
+	if !hasAVX2 {
+		t.Skip("AVX2 not supported")
+	}
+	f := make(thinPoly, 4)
+	g := make(thinPoly, 4)
+	p := make(thinPoly, 8)
+	q := make(thinPoly, 8)
+	for i := 0; i < 10000; i++ {
+		for j := range f {
+			f[j] = int32(rand.Intn(9829))
+			g[j] = int32(rand.Intn(9829))
+			if i == 0 {
+				f[j], g[j] = 9828, 9828
+			}
+		}
+		for j := range p {
+			p[j], q[j] = -1, -1
+		}
+		x4MulGeneric(p, f, g)
+		x4MulAVX2(q, f, g)
+		for j := range p {
+			if p[j] != q[j] {
+				t.Fatalf("f=%v, g=%v: x4MulAVX2 gives %v, x4MulGeneric %v", f, g, q, p)
+			}
+		}
+	}
+}
+
+func BenchmarkX4MulAVX2(b *testing.B) {
+	if !hasAVX2 {
+		b.Skip("AVX2 not supported")
+	}
+	f := thinPoly(randPoly(new([768]int32))[:4])
+	g := thinPoly(randPoly(new([768]int32))[:4])
+	p := make(thinPoly, 8)
+	for i := 0; i < b.N; i++ {
+		x4MulAVX2(p, f, g)
+	}
+}
+
+func TestKaratsuba5SquareAsm(t *testing.T) {
+	if !hasAVX2 {
+		t.Skip("AVX2 not supported")
+	}
+	f := make(thinPoly, 8)
+	p := make(thinPoly, 16)
+	q := make(thinPoly, 16)
+	for i := 0; i < 10000; i++ {
+		for j := range f {
+			f[j] = int32(rand.Intn(9829))
+			if i == 0 {
+				f[j] = 9828
+			}
+		}
+		for j := range p {
+			p[j], q[j] = -1, -1
+		}
+		karatsuba5SquareGeneric(p, f, nil)
+		karatsuba5SquareAVX2(q, f, nil)
+		for j := range p {
+			if Freeze(p[j]) != Freeze(q[j]) {
+				t.Fatalf("f=%v: karatsuba5SquareAVX2 gives %v, karatsuba5SquareGeneric %v", f, q, p)
+			}
+		}
+	}
+}
+
+func BenchmarkKaratsuba5SquareAVX2(b *testing.B) {
+	if !hasAVX2 {
+		b.Skip("AVX2 not supported")
+	}
+	f := thinPoly(randPoly(new([768]int32))[:8])
+	p := make(thinPoly, 16)
+	for i := 0; i < b.N; i++ {
+		karatsuba5SquareAVX2(p, f, nil)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math/bits"
+)
+
+// zq holds the constants used to reduce modulo an odd prime q < 2^15, as
+// Freeze does for 9829.
+type zq struct {
+	q    int32
+	m    uint64 // floor(2^64 / q)
+	bias int64  // a multiple of q no smaller than 2^31
+}
+
+func newZq(q int32) (zq, error) {
+	if q < 3 || q >= 1<<15 || !isPrime(q) {
+		return zq{}, errors.New("modulus is not an odd prime below 2^15")
+	}
+	m, _ := bits.Div64(1, 0, uint64(q))
+	bias := int64(q) * ((1<<31 + int64(q) - 1) / int64(q))
+	return zq{q: q, m: m, bias: bias}, nil
+}
+
+// GenerateFreezeConstants returns the constants FreezeForPrime uses for q:
+// c1 and c2 approximate 2^17/q and 2^22/q, and off rounds the second
+// quotient to the nearest integer. For q = 9829 they are 13, 427 and 2^21, the
+// constants of Freeze.
+func GenerateFreezeConstants(q int32) (c1, c2, off int32) {
+	return (1<<17 + q/2) / q, (1<<22 + q/2) / q, 1 << 21
+}
+
+// FreezeForPrime reduces x modulo q in the manner of Freeze, for q an odd
+// prime in (2^11, 2^14) and x in (-2^25, +2^25). The first step brings x
+// within a few hundred multiples of q, and the second within (-q, q). The
+// constants are derived on every call; callers reducing many values modulo the
+// same q should consider the cost of the two divisions.
+func FreezeForPrime(x, q int32) int32 {
+	c1, c2, off := GenerateFreezeConstants(q)
+	x -= q * ((c1 * x) >> 17)
+	x -= q * ((c2*x + off) >> 22)
+	y := x + q
+	v := subtle.ConstantTimeLessOrEq(int(x), -1)
+	return int32(subtle.ConstantTimeSelect(v, int(y), int(x)))
+}
+
+// CenteredFreeze reduces x modulo 9829 into [-4914, 4914] instead of
+// [0, 9828], so that small negative values stay small. x must be in the
+// domain of Freeze. Its running time does not depend on x.
+func CenteredFreeze(x int32) int32 {
+	x = Freeze(x)
+	// subtract 9829 if x > 4914
+	return x - 9829&((4914-x)>>31)
+}
+
+// montNegQinv is -1/9829 modulo 2^32, and montR is 2^32 modulo 9829.
+const (
+	montNegQinv = 3478709395
+	montR       = 8824
+)
+
+// MontgomeryReduce returns x/2^32 modulo q, in (-q, q), for q odd and
+// |x| < q*2^31. It adds to x the multiple t*q of q that clears its low 32
+// bits, t being the low 32 bits of x times -1/q, and shifts the result right.
+// The constant -1/q modulo 2^32 is precomputed for 9829 and derived by Newton
+// iteration for other moduli.
+func MontgomeryReduce(x int64, q int32) int32 {
+	qinv := uint32(montNegQinv)
+	if q != 9829 {
+		// each step doubles the number of correct low bits of 1/q,
+		// starting from the three given by q itself
+		u := uint32(q)
+		for i := 0; i < 4; i++ {
+			u *= 2 - uint32(q)*u
+		}
+		qinv = -u
+	}
+	t := int32(uint32(x) * qinv)
+	return int32((x + int64(t)*int64(q)) >> 32)
+}
+
+// freezeMontgomery applies Freeze to every element of p by Montgomery
+// reduction, for any int32 input: multiplying by 2^32 modulo 9829 beforehand
+// cancels the division by 2^32. Its output is the same as freezeGeneric's.
+func freezeMontgomery(p []int32) {
+	for i := range p {
+		r := MontgomeryReduce(int64(p[i])*montR, 9829)
+		p[i] = r + 9829&(r>>31)
+	}
+}
+
+// isPrime reports whether q is prime, by trial division.
+func isPrime(q int32) bool {
+	if q < 2 {
+		return false
+	}
+	for d := int32(2); d*d <= q; d++ {
+		if q%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reduce returns x modulo q, for x < 2^63, in constant time. The quotient
+// estimated with m is short by at most one, which a masked subtraction fixes.
+func (z zq) reduce(x uint64) int32 {
+	hi, _ := bits.Mul64(x, z.m)
+	r := int64(x - hi*uint64(z.q))
+	r -= int64(z.q)
+	r += int64(z.q) & (r >> 63)
+	return int32(r)
+}
+
+// freeze returns x modulo q in [0, q-1], for any x.
+func (z zq) freeze(x int32) int32 {
+	return z.reduce(uint64(int64(x) + z.bias))
+}
+
+// inverse returns the multiplicative inverse of x modulo q, computed as
+// x^(q-2) by Fermat's little theorem. The result for x = 0 is 0.
+func (z zq) inverse(x int32) int32 {
+	r := int32(1)
+	x = z.freeze(x)
+	for e := z.q - 2; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = z.freeze(r * x)
+		}
+		x = z.freeze(x * x)
+	}
+	return r
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2017 Pedro Martelletto. All rights reserved.
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file.
+
+package karatsuba768
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestZqFreeze(t *testing.T) {
+	for _, q := range []int32{3, 4591, 7879, 9829, 32749} {
+		z, err := newZq(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xs := []int32{0, 1, -1, q - 1, q, -q, math.MaxInt32, math.MinInt32}
+		for i := 0; i < 1<<16; i++ {
+			xs = append(xs, int32(rand.Uint32()))
+		}
+		for _, x := range xs {
+			want := int32((int64(x)%int64(q) + int64(q)) % int64(q))
+			if y := z.freeze(x); y != want {
+				t.Fatalf("freeze(%d)=%d != %d for q=%d", x, y, want, q)
+			}
+			if q == 9829 && x > -165191050 && x < 165191050 && Freeze(x) != want {
+				t.Fatalf("Freeze(%d)=%d != %d", x, Freeze(x), want)
+			}
+		}
+		for i := 0; i < 1<<16; i++ {
+			x := rand.Uint64() >> 1
+			if y := z.reduce(x); uint64(y) != x%uint64(q) {
+				t.Fatalf("reduce(%d)=%d != %d for q=%d", x, y, x%uint64(q), q)
+			}
+		}
+	}
+}
+
+func TestZqInverse(t *testing.T) {
+	z, err := newZq(4591)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := int32(1); x < 4591; x++ {
+		if y := z.freeze(x * z.inverse(x)); y != 1 {
+			t.Fatalf("x*inverse(x)=%d for x=%d", y, x)
+		}
+	}
+	if _, err := newZq(4593); err == nil {
+		t.Fatal("newZq accepted a composite")
+	}
+}
+
+func TestGenerateFreezeConstants(t *testing.T) {
+	c1, c2, off := GenerateFreezeConstants(9829)
+	if c1 != 13 || c2 != 427 || off != 1<<21 {
+		t.Fatalf("constants for 9829 are %d, %d, %d", c1, c2, off)
+	}
+}
+
+func TestFreezeForPrime(t *testing.T) {
+	step := int32(1)
+	if testing.Short() {
+		step = 997
+	}
+	for x := int32(-165191049); x < 165191050; x += step {
+		if y := FreezeForPrime(x, 9829); y != Freeze(x) {
+			t.Fatalf("FreezeForPrime(%d, 9829)=%d != %d", x, y, Freeze(x))
+		}
+	}
+	for x := int32(-1<<25 + 1); x < 1<<25; x += step {
+		want := (x%4591 + 4591) % 4591
+		if y := FreezeForPrime(x, 4591); y != want {
+			t.Fatalf("FreezeForPrime(%d, 4591)=%d != %d", x, y, want)
+		}
+	}
+	for _, q := range []int32{2053, 4621, 5167, 6343, 6983, 7177, 7879, 16381} {
+		for i := 0; i < 1<<16; i++ {
+			x := rand.Int31n(1<<26-1) - (1<<25 - 1)
+			want := (x%q + q) % q
+			if y := FreezeForPrime(x, q); y != want {
+				t.Fatalf("FreezeForPrime(%d, %d)=%d != %d", x, q, y, want)
+			}
+		}
+	}
+}
+
+func TestCenteredFreezeAndBack(t *testing.T) {
+	step := int32(1)
+	if testing.Short() {
+		step = 997
+	}
+	for x := int32(-165191049); x < 165191050; x += step {
+		y := CenteredFreeze(x)
+		if y < -4914 || y > 4914 {
+			t.Fatalf("CenteredFreeze(%d)=%d out of range", x, y)
+		}
+		if z := Freeze(y + 9829); z != Freeze(x) {
+			t.Fatalf("Freeze(CenteredFreeze(%d)+9829)=%d != %d", x, z, Freeze(x))
+		}
+	}
+	for x, want := range map[int32]int32{0: 0, 4914: 4914, 4915: -4914, 9828: -1, -1: -1} {
+		if y := CenteredFreeze(x); y != want {
+			t.Fatalf("CenteredFreeze(%d)=%d, want %d", x, y, want)
+		}
+	}
+
+	f := randPoly(new([768]int32))
+	h := new([768]int32)
+	CenteredPoly(h, f)
+	for i := range h {
+		if Freeze(h[i]) != f[i] || h[i] != CenteredFreeze(f[i]) {
+			t.Fatalf("CenteredPoly: coefficient %d is %d for %d", i, h[i], f[i])
+		}
+	}
+	if n := Norm2(h); n != Norm2Centered(f) {
+		t.Fatalf("Norm2 of CenteredPoly(f) is %d, Norm2Centered(f) %d", n, Norm2Centered(f))
+	}
+	CenteredPoly(f, f)
+	if !Equal(f, h) {
+		t.Fatal("CenteredPoly in place differs")
+	}
+}
+
+func TestMontgomeryReduce(t *testing.T) {
+	for _, q := range []int32{3, 4591, 7879, 9829, 32749} {
+		// 2^32 modulo q
+		r := int64(1<<32) % int64(q)
+		xs := []int64{0, 1, -1, int64(q), int64(q)<<31 - 1, -(int64(q)<<31 - 1)}
+		for i := 0; i < 1<<16; i++ {
+			xs = append(xs, rand.Int63n(int64(q)<<32)-int64(q)<<31)
+		}
+		for _, x := range xs {
+			y := MontgomeryReduce(x, q)
+			if y <= -q || y >= q {
+				t.Fatalf("MontgomeryReduce(%d, %d)=%d out of range", x, q, y)
+			}
+			// y*2^32 = x modulo q
+			if d := (int64(y)*r - x) % int64(q); d != 0 {
+				t.Fatalf("MontgomeryReduce(%d, %d)=%d is wrong", x, q, y)
+			}
+		}
+	}
+}
+
+func TestFreezeMontgomery(t *testing.T) {
+	p := make([]int32, 1<<16)
+	for i := range p {
+		p[i] = int32(rand.Uint32())
+	}
+	p[0], p[1], p[2] = math.MinInt32, math.MaxInt32, -1
+	z, err := newZq(9829)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]int32, len(p))
+	for i := range p {
+		want[i] = z.freeze(p[i])
+	}
+	freezeMontgomery(p)
+	for i := range p {
+		if p[i] != want[i] {
+			t.Fatalf("p[%d]=%d != %d", i, p[i], want[i])
+		}
+	}
+}
+
+func benchmarkFreeze768(b *testing.B, freeze func([]int32)) {
+	p := make([]int32, 768)
+	q := make([]int32, 768)
+	for i := range p {
+		p[i] = rand.Int31n(2*165191049) - 165191049
+	}
+	b.SetBytes(4 * 768)
+	for i := 0; i < b.N; i++ {
+		copy(q, p)
+		freeze(q)
+	}
+}
+
+func BenchmarkFreezeNewton(b *testing.B) {
+	benchmarkFreeze768(b, freezeGeneric)
+}
+
+func BenchmarkFreezeMontgomery(b *testing.B) {
+	benchmarkFreeze768(b, freezeMontgomery)
+}